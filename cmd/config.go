@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cicovic-andrija/2048/core"
+	"github.com/cicovic-andrija/2048/keys"
+)
+
+const configfile = "config.json"
+
+// config holds the defaults of the settings, flags given on the command
+// line override them
+type config struct {
+	Player    string      `json:"player"`
+	Size      int         `json:"size"`
+	Target    int         `json:"target"`
+	Undos     int         `json:"undos"`
+	UndoMode  string      `json:"undoMode"`
+	Interface string      `json:"interface"` // terminal or text
+	Theme     string      `json:"theme"`
+	Themes    string      `json:"themesFile"` // user-defined themes
+	Fill      string      `json:"fill"`       // auto, color or pattern
+	Labels    bool        `json:"labels"`
+	Animation string      `json:"animation"` // duration, such as "120ms", or "0" for none
+	Keys      keys.Keymap `json:"keys"`
+	SaveDir   string      `json:"saveDir"` // empty for the default data directory
+}
+
+const (
+	terminalInterface = "terminal"
+	textInterface     = "text"
+)
+
+// configPath returns the path of the default config file:
+// <user config directory>/2048/config.json
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "2048", configfile), nil
+}
+
+// currentConfig returns the settings as they are now,
+// before parsing they are the defaults of the flags
+func currentConfig() config {
+	c := config{
+		Player:    player,
+		Size:      size,
+		Target:    target,
+		Undos:     undos,
+		UndoMode:  undomode,
+		Interface: terminalInterface,
+		Theme:     theme,
+		Themes:    themefile,
+		Fill:      fill,
+		Labels:    labels,
+		Animation: animation.String(),
+		Keys:      keymap,
+		SaveDir:   savedir,
+	}
+	if textinterface {
+		c.Interface = textInterface
+	}
+	return c
+}
+
+// loadConfig reads the config file over the current settings. The default
+// file doesn't have to exist, a file given with -config does.
+func loadConfig() (config, error) {
+	c := currentConfig()
+
+	path := configfilePath
+	if path == "" {
+		var err error
+		if path, err = configPath(); err != nil {
+			return c, nil // no config directory, nothing to load
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && configfilePath == "" {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+
+	// the keys in the file replace only the bindings of their actions
+	c.Keys = keys.Keymap{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return c, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	km := keys.DefaultKeymap()
+	km.Merge(c.Keys)
+	c.Keys = km
+
+	switch c.Interface {
+	case terminalInterface, textInterface:
+	default:
+		return c, fmt.Errorf("invalid config file %s: interface must be %s or %s", path, terminalInterface, textInterface)
+	}
+	return c, nil
+}
+
+// applyConfig sets the settings whose flags were not given
+func applyConfig(c config) error {
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	if !given["player"] {
+		player = c.Player
+	}
+	if !given["size"] {
+		size = c.Size
+	}
+	if !given["target"] {
+		target = c.Target
+	}
+	if !given["undos"] {
+		undos = c.Undos
+	}
+	if !given["undomode"] {
+		undomode = c.UndoMode
+	}
+	if !given["textinterface"] && !given["terminterface"] {
+		textinterface = c.Interface == textInterface
+		terminterface = !textinterface
+	}
+	if !given["theme"] {
+		theme = c.Theme
+	}
+	if !given["themes"] {
+		themefile = c.Themes
+	}
+	if !given["fill"] {
+		fill = c.Fill
+	}
+	if !given["labels"] {
+		labels = c.Labels
+	}
+	if !given["animation"] {
+		d, err := time.ParseDuration(c.Animation)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid animation %q in the config file", c.Animation)
+		}
+		animation = d
+	}
+	if !given["savedir"] {
+		savedir = c.SaveDir
+	}
+	keymap = c.Keys
+	return nil
+}
+
+// printConfig prints the effective settings in the format of the config file
+func printConfig() error {
+	c := currentConfig()
+
+	km, err := effectiveKeymap()
+	if err != nil {
+		return err
+	}
+	c.Keys = km
+
+	if c.SaveDir == "" {
+		if c.SaveDir, err = core.DataDir(); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -4,39 +4,174 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/cicovic-andrija/2048/core"
+	"github.com/cicovic-andrija/2048/hosti"
+	"github.com/cicovic-andrija/2048/keys"
+	"github.com/cicovic-andrija/2048/sim"
+	"github.com/cicovic-andrija/2048/solver"
 	"github.com/cicovic-andrija/2048/termi"
 	"github.com/cicovic-andrija/2048/texti"
 )
 
 var (
 	// used in this package
-	local         bool // local game
-	hosted        bool // hosted game
-	textinterface bool // text interface
-	terminterface bool // terminal interface
+	local          bool          // local game
+	hosted         bool          // hosted game
+	textinterface  bool          // text interface
+	terminterface  bool          // terminal interface
+	resume         bool          // resume the last game
+	replayfile     string        // play back a recorded game
+	recordfile     string        // record the game
+	autoplay       bool          // let the solver play
+	delay          time.Duration // delay between autoplay moves
+	simulate       int           // number of games to simulate
+	strategy       string        // strategy of the simulated games
+	format         string        // format of the simulation report
+	workers        int           // number of games simulated in parallel
+	scores         bool          // print the high scores
+	keysfile       string        // key bindings file
+	bindings       bindList      // key bindings given on the command line
+	keymap         keys.Keymap   // key bindings of the config file
+	configfilePath string        // config file, instead of the default one
+	printconfig    bool          // print the effective configuration
+	theme          string        // color theme of the terminal interface
+	themefile      string        // user-defined themes
+	fill           string        // how blocks are told apart
+	labels         bool          // always label the numbers
+	animation      time.Duration // of a move in the terminal interface
+	savedir        string        // directory of saved games and high scores
 
 	// passed to and validated later in other packages
-	player string // player name
-	size   int    // board size
-	target int    // end-game block
-	undos  int    // number of undos
+	player   string // player name
+	size     int    // board size
+	rows     int    // number of board rows, overrides size
+	cols     int    // number of board columns, overrides size
+	target   int    // end-game block
+	undos    int    // number of undos
+	seed     int64  // random seed
+	undomode string // classic or unlimited undos
+	addr     string // address of the hosted game server
 )
 
 func init() {
+	keymap = keys.DefaultKeymap()
+
 	flag.BoolVar(&local, "local", true, "Local game")
 	flag.BoolVar(&hosted, "hosted", false, "Hosted game (overrides -local)")
 	flag.BoolVar(&terminterface, "terminterface", true, "Terminal graphics")
 	flag.BoolVar(&textinterface, "textinterface", false, "Text interface (overrides -terminterface)")
+	flag.BoolVar(&resume, "resume", false, "Resume the last unfinished game")
+	flag.StringVar(&replayfile, "replay", "", "Play back the game recorded in `file`")
+	flag.StringVar(&recordfile, "record", "", "Record the game to `file` for later playback")
+	flag.BoolVar(&autoplay, "autoplay", false, "Watch the computer play (terminal graphics only)")
+	flag.DurationVar(&delay, "delay", 200*time.Millisecond, "Delay between moves in autoplay")
+	flag.IntVar(&simulate, "simulate", 0, "Play `N` games without a user interface and print statistics about them")
+	flag.StringVar(&strategy, "strategy", "expectimax", "Strategy of simulated games: "+strings.Join(sim.Strategies, ", "))
+	flag.StringVar(&format, "format", "text", "Format of the simulation report: "+strings.Join(sim.Formats, ", "))
+	flag.IntVar(&workers, "workers", 0, "Number of games simulated in parallel (0 is one per CPU)")
+	flag.BoolVar(&scores, "scores", false, "Print the high scores and exit")
+	flag.StringVar(&keysfile, "keys", "", "Read the key bindings of both interfaces from the JSON `file`")
+	flag.Var(&bindings, "bind", "Bind `action=key[,key...]` in the chosen interface (repeatable), actions: "+strings.Join(actionNames(), ", "))
+	flag.StringVar(&configfilePath, "config", "", "Read the settings from the JSON `file` instead of the default config file")
+	flag.BoolVar(&printconfig, "print-config", false, "Print the effective configuration (config file and flags) and exit")
+	flag.StringVar(&theme, "theme", "classic", "Color `theme` of the terminal interface: "+strings.Join(termi.ThemeNames(), ", ")+", or one of the -themes file")
+	flag.StringVar(&themefile, "themes", "", "Read user-defined color themes from the JSON `file`")
+	flag.StringVar(&fill, "fill", termi.FillAuto, "How blocks are told apart: by color, by pattern, or auto (patterns if the terminal has 8 colors or less)")
+	flag.BoolVar(&labels, "labels", false, "Always label the numbers in plain text, also on large blocks")
+	flag.DurationVar(&animation, "animation", termi.DefaultAnimation, "Duration of the animation of a move in terminal graphics, 0 turns animations off")
+	flag.StringVar(&savedir, "savedir", "", "`Directory` of the saved game and high scores (default is the user data directory)")
+	flag.StringVar(&addr, "addr", ":2048", "TCP `address` on which hosted games are served")
 	flag.StringVar(&player, "player", "Player", "Player's `name`")
-	flag.IntVar(&size, "size", 4, "Board size: 4 (classic), 5 or 6")
+	flag.IntVar(&size, "size", 4, "Board size: 2 to 16 (4 is classic)")
+	flag.IntVar(&rows, "rows", 0, "Number of board rows, for rectangular boards (overrides -size)")
+	flag.IntVar(&cols, "cols", 0, "Number of board columns, for rectangular boards (overrides -size)")
 	flag.IntVar(&target, "target", 2048, "End-game `block`: 2048, 4096 or 8192")
 	flag.IntVar(&undos, "undos", 3, "Number of undos")
+	flag.StringVar(&undomode, "undomode", "classic", "Undo `mode`: classic (only the last move) or unlimited (any number of moves)")
+	flag.Int64Var(&seed, "seed", 0, "Random `seed` for a reproducible game (0 picks one at random)")
+}
+
+// bindList collects the repeated -bind flags
+type bindList []string
+
+func (l *bindList) String() string {
+	return strings.Join(*l, " ")
 }
 
-func parseCmdline() {
+func (l *bindList) Set(spec string) error {
+	// parsed now to report errors along with the other flags
+	if err := make(keys.Bindings).Set(spec); err != nil {
+		return err
+	}
+	*l = append(*l, spec)
+	return nil
+}
+
+func actionNames() []string {
+	names := make([]string, len(keys.Actions))
+	for i, a := range keys.Actions {
+		names[i] = a.String()
+	}
+	return names
+}
+
+// effectiveKeymap returns the bindings of the config file, overridden
+// by the keys file, overridden by the -bind flags for the chosen interface
+func effectiveKeymap() (keys.Keymap, error) {
+	km := keys.Keymap{Term: keymap.Term.Clone(), Text: keymap.Text.Clone()}
+	if keysfile != "" {
+		loaded, err := keys.ReadKeymap(keysfile)
+		if err != nil {
+			return km, err
+		}
+		km.Merge(loaded)
+	}
+
+	b := km.Term
+	if textinterface {
+		b = km.Text
+	}
+	for _, spec := range bindings {
+		if err := b.Set(spec); err != nil {
+			return km, err
+		}
+	}
+	return km, nil
+}
+
+// keyBindings returns the effective bindings of the chosen interface
+func keyBindings() (keys.Bindings, error) {
+	km, err := effectiveKeymap()
+	if err != nil {
+		return nil, err
+	}
+	if textinterface {
+		return km.Text, nil
+	}
+	return km.Term, nil
+}
+
+// termOptions returns the options of the terminal interface,
+// nil bindings stand for the default ones
+func termOptions(b keys.Bindings) termi.Options {
+	return termi.Options{Keys: b, Theme: theme, Fill: fill, Labels: labels, Animation: animation}
+}
+
+func parseCmdline() error {
 	flag.Parse()
 
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := applyConfig(c); err != nil {
+		return err
+	}
+
 	if textinterface {
 		terminterface = false
 	}
@@ -44,26 +179,190 @@ func parseCmdline() {
 	if hosted {
 		local = false
 	}
+
+	if rows == 0 {
+		rows = size
+	}
+	if cols == 0 {
+		cols = size
+	}
+
+	core.SetDataDir(savedir)
+	if themefile != "" {
+		if err := termi.LoadThemes(themefile); err != nil {
+			return err
+		}
+	}
+	if err := termi.CheckFill(fill); err != nil {
+		return err
+	}
+	return termi.CheckTheme(theme)
+}
+
+func newLocalGame() (*core.Game, error) {
+	var game *core.Game
+
+	mode, err := core.ParseUndoMode(undomode)
+	if err != nil {
+		return nil, err
+	}
+
+	if resume {
+		game, err = core.LoadGame()
+	} else {
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		game, err = core.NewRectGame(player, rows, cols, target, undos, seed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error in game initialization: %v", err)
+	}
+
+	if !resume {
+		game.SetUndoMode(mode)
+	}
+	game.Autosave = true
+	return game, nil
+}
+
+func printAutoplaySummary(game *core.Game) {
+	fmt.Printf(
+		"Autoplay: score %d, highest block %d, moves %d\n",
+		game.Score(), game.HighestBlock(), game.Moves(),
+	)
+	for block := 2048; block <= 8192; block *= 2 {
+		reached := "no"
+		if game.HighestBlock() >= block {
+			reached = "yes"
+		}
+		fmt.Printf("  reached %d: %s\n", block, reached)
+	}
+}
+
+func printHighScores() error {
+	h, err := core.LoadHighScores()
+	if err != nil {
+		return err
+	}
+	if len(h.Leaderboards) == 0 {
+		fmt.Println("No high scores yet")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for i, lb := range h.Leaderboards {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%v\n", lb.Board)
+		fmt.Fprintf(w, "#\tPlayer\tScore\tMax tile\tMoves\tDuration\tDate\n")
+		for rank, s := range lb.Scores {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%v\t%s\n",
+				rank+1, s.Player, s.Score, s.MaxTile, s.Moves,
+				s.Duration.Round(time.Second), s.Date.Format("2006-01-02 15:04"))
+		}
+	}
+	return w.Flush()
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
 }
 
 func main() {
-	parseCmdline()
+	if err := parseCmdline(); err != nil {
+		fatal(err)
+	}
 
-	if local && textinterface {
-		if err := texti.NewTextGame(player, size, target, undos); err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+	if printconfig {
+		if err := printConfig(); err != nil {
+			fatal(err)
 		}
+		return
 	}
 
-	if local && terminterface {
-		if err := termi.NewTerminalGraphicsGame(player, size, target, undos); err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+	if scores {
+		if err := printHighScores(); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	if replayfile != "" {
+		replay, err := core.ReadReplay(replayfile)
+		if err != nil {
+			fatal(err)
+		}
+		if err := termi.PlayReplay(replay, termOptions(nil)); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	if simulate > 0 {
+		if err := sim.CheckFormat(format); err != nil {
+			fatal(err)
+		}
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		report, err := sim.Run(sim.Config{
+			Games:    simulate,
+			Strategy: strategy,
+			Rows:     rows,
+			Cols:     cols,
+			Target:   target,
+			Seed:     seed,
+			Workers:  workers,
+		})
+		if err != nil {
+			fatal(err)
+		}
+		if err := report.Write(os.Stdout, format); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	if local {
+		b, err := keyBindings()
+		if err != nil {
+			fatal(err)
+		}
+
+		game, err := newLocalGame()
+		if err != nil {
+			fatal(err)
+		}
+
+		if autoplay {
+			game.Autosave = false // don't overwrite the player's last game
+			err = termi.NewAutoplayGame(game, solver.New(solver.DefaultDepth).BestMove, delay, termOptions(nil))
+			if err == nil {
+				printAutoplaySummary(game)
+			}
+		} else if textinterface {
+			err = texti.NewTextGame(game, b)
+		} else {
+			err = termi.NewTerminalGraphicsGame(game, termOptions(b))
+		}
+		if err != nil {
+			fatal(err)
+		}
+
+		if recordfile != "" {
+			if err := game.Replay().WriteFile(recordfile); err != nil {
+				fatal(err)
+			}
 		}
 	}
 
 	if hosted {
-		fmt.Printf("Hosted games are not yet supported.\n")
+		fmt.Printf("Serving hosted games on %s\n", addr)
+		if err := hosti.ListenAndServe(addr); err != nil {
+			fatal(err)
+		}
 	}
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cicovic-andrija/2048/keys"
+)
+
+func TestEffectiveKeymapLayers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "2048")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configfilePath = filepath.Join(dir, "config.json")
+	keysfile = filepath.Join(dir, "keys.json")
+	bindings = bindList{"hint=x"}
+	defer func() {
+		configfilePath, keysfile, bindings, keymap = "", "", nil, keys.DefaultKeymap()
+	}()
+	config := `{"keys": {"term": {"undo": ["u"], "redo": ["r"]}}}`
+	if err := ioutil.WriteFile(configfilePath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keysfile, []byte(`{"term": {"quit": ["q"], "redo": ["y"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := applyConfig(c); err != nil {
+		t.Fatal(err)
+	}
+	textinterface = false
+	km, err := effectiveKeymap()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[keys.Action][]string{
+		keys.Undo:  {"u"},  // config file
+		keys.Redo:  {"y"},  // keys file over the config file
+		keys.Quit:  {"q"},  // keys file
+		keys.Hint:  {"x"},  // -bind
+		keys.Up:    {"Up"}, // default
+		keys.Stats: {"s"},  // default
+	}
+	for a, k := range want {
+		if got := km.Term[a]; !reflect.DeepEqual(got, k) {
+			t.Errorf("%v = %q, want %q", a, got, k)
+		}
+	}
+	if got := km.Text[keys.Undo]; !reflect.DeepEqual(got, []string{"u"}) {
+		t.Errorf("text %v = %q, want the default", keys.Undo, got)
+	}
+}
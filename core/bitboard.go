@@ -0,0 +1,222 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+const (
+	BitboardSize = 4
+
+	// largest block a Bitboard can hold, two such blocks never merge
+	MaxBitboardBlock = 1 << maxBitboardRank
+
+	maxBitboardRank = 15
+)
+
+// Bitboard is a 4x4 board packed into 64 bits, 4 bits per cell holding
+// the rank of the block (log2 of its value, 0 for no block). Rows are
+// moved with precomputed lookup tables, which makes it much faster than
+// Board, at the cost of supporting only 4x4 boards with blocks up to
+// MaxBitboardBlock.
+//
+// Cell (i,j) is stored in bits 16*i+4*j to 16*i+4*j+3.
+type Bitboard uint64
+
+var (
+	// results of pushing a row to the left and to the right,
+	// and the points scored by either, indexed by the row
+	rowLeft  [1 << 16]uint16
+	rowRight [1 << 16]uint16
+	rowScore [1 << 16]int32
+)
+
+func init() {
+	for row := 0; row < 1<<16; row++ {
+		var (
+			line   [BitboardSize]uint16
+			result [BitboardSize]uint16
+		)
+		for k := range line {
+			line[k] = uint16(row>>(4*k)) & 0xf
+		}
+
+		// same algorithm as Board.Move, on a single line
+		fence, mergeable, score := 0, false, int32(0)
+		for _, r := range line {
+			if r == 0 {
+				continue
+			}
+			if mergeable && result[fence-1] == r && r < maxBitboardRank {
+				result[fence-1]++
+				score += 1 << (r + 1)
+				mergeable = false
+				continue
+			}
+			result[fence] = r
+			fence++
+			mergeable = true
+		}
+
+		left := uint16(0)
+		for k, r := range result {
+			left |= r << (4 * k)
+		}
+		rowLeft[row] = left
+		rowRight[reverseRow(uint16(row))] = reverseRow(left)
+		rowScore[row] = score
+	}
+}
+
+func reverseRow(row uint16) uint16 {
+	return row>>12 | (row>>4)&0x00f0 | (row<<4)&0x0f00 | row<<12
+}
+
+// BitboardOf packs a 4x4 board into a Bitboard.
+func BitboardOf(b Board) (Bitboard, error) {
+	if b.Rows() != BitboardSize || b.Cols() != BitboardSize {
+		return 0, fmt.Errorf("bitboard must be %dx%d", BitboardSize, BitboardSize)
+	}
+
+	x := Bitboard(0)
+	for k, block := range b.cells {
+		if block > MaxBitboardBlock {
+			return 0, errors.New("block too large for a bitboard")
+		}
+		if block != 0 {
+			x |= Bitboard(bits.TrailingZeros(uint(block))) << (4 * k)
+		}
+	}
+	return x, nil
+}
+
+// Board unpacks the bitboard.
+func (x Bitboard) Board() Board {
+	b := NewBoard(BitboardSize, BitboardSize)
+	for k := range b.cells {
+		if r := (x >> (4 * k)) & 0xf; r != 0 {
+			b.cells[k] = 1 << r
+		}
+	}
+	return b
+}
+
+func (x Bitboard) Rows() int {
+	return BitboardSize
+}
+
+func (x Bitboard) Cols() int {
+	return BitboardSize
+}
+
+// Row returns the ranks of the blocks of the i-th row, column j
+// in bits 4*j to 4*j+3.
+func (x Bitboard) Row(i int) uint16 {
+	return uint16(x >> (16 * i))
+}
+
+// Block returns the block at (i,j), or -1 if the cell is outside the board.
+func (x Bitboard) Block(i int, j int) int {
+	if i < 0 || i >= BitboardSize || j < 0 || j >= BitboardSize {
+		return -1
+	}
+	if r := (x >> (16*i + 4*j)) & 0xf; r != 0 {
+		return 1 << r
+	}
+	return 0
+}
+
+// Transpose mirrors the board along its main diagonal, so that
+// its columns can be treated as rows.
+func (x Bitboard) Transpose() Bitboard {
+	a1 := x & 0xf0f00f0ff0f00f0f
+	a2 := x & 0x0000f0f00000f0f0
+	a3 := x & 0x0f0f00000f0f0000
+	a := a1 | a2<<12 | a3>>12
+	b1 := a & 0xff00ff0000ff00ff
+	b2 := a & 0x00ff00ff00000000
+	b3 := a & 0x00000000ff00ff00
+	return b1 | b2>>24 | b3<<24
+}
+
+// Move pushes the blocks in the given direction, see Board.Move.
+func (x Bitboard) Move(dir Direction) (next Bitboard, gained int, moved bool) {
+	table := &rowLeft
+	if dir == Right || dir == Down {
+		table = &rowRight
+	}
+
+	src := x
+	if dir == Up || dir == Down {
+		src = x.Transpose()
+	}
+
+	for i := 0; i < BitboardSize; i++ {
+		row := src.Row(i)
+		next |= Bitboard(table[row]) << (16 * i)
+		gained += int(rowScore[row])
+	}
+
+	if dir == Up || dir == Down {
+		next = next.Transpose()
+	}
+	return next, gained, next != x
+}
+
+// Spawn returns the board with a block placed on the cell,
+// which must be an empty cell of the board.
+func (x Bitboard) Spawn(c Cell, block int) Bitboard {
+	switch x.Block(c.Row, c.Col) {
+	case 0:
+	case -1:
+		panic(fmt.Sprintf("core: spawn on (%d,%d), outside the board", c.Row, c.Col))
+	default:
+		panic(fmt.Sprintf("core: spawn on a non-empty cell (%d,%d)", c.Row, c.Col))
+	}
+	return x | Bitboard(bits.TrailingZeros(uint(block)))<<(16*c.Row+4*c.Col)
+}
+
+func (x Bitboard) EmptyCells() []Cell {
+	var empty []Cell
+	for k := 0; k < BitboardSize*BitboardSize; k++ {
+		if (x>>(4*k))&0xf == 0 {
+			empty = append(empty, Cell{k / BitboardSize, k % BitboardSize})
+		}
+	}
+	return empty
+}
+
+// CanMove reports whether a move in any direction would move a block.
+func (x Bitboard) CanMove() bool {
+	t := x.Transpose()
+	for i := 0; i < BitboardSize; i++ {
+		if rowLeft[x.Row(i)] != x.Row(i) || rowRight[x.Row(i)] != x.Row(i) ||
+			rowLeft[t.Row(i)] != t.Row(i) || rowRight[t.Row(i)] != t.Row(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// Highest returns the value of the largest block on the board.
+func (x Bitboard) Highest() int {
+	highest := Bitboard(0)
+	for k := 0; k < BitboardSize*BitboardSize; k++ {
+		if r := (x >> (4 * k)) & 0xf; r > highest {
+			highest = r
+		}
+	}
+	if highest == 0 {
+		return 0
+	}
+	return 1 << highest
+}
+
+func (x Bitboard) Moved(dir Direction) (Position, int, bool) {
+	return x.Move(dir)
+}
+
+func (x Bitboard) Spawned(c Cell, block int) Position {
+	return x.Spawn(c, block)
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// returns a random 4x4 board, with about a third of the cells empty
+// and blocks up to 2^maxRank
+func randomBoard(rng *rand.Rand, maxRank int) Board {
+	b := NewBoard(BitboardSize, BitboardSize)
+	for i := range b.cells {
+		if rng.Intn(3) == 0 {
+			continue
+		}
+		b.cells[i] = 1 << uint(1+rng.Intn(maxRank))
+	}
+	return b
+}
+
+func TestBitboardMatchesBoard(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 20000; n++ {
+		// mostly small blocks, so that there are many merges
+		maxRank := 4
+		if n%2 == 0 {
+			maxRank = maxBitboardRank - 1
+		}
+		b := randomBoard(rng, maxRank)
+		x, err := BitboardOf(b)
+		if err != nil {
+			t.Fatalf("BitboardOf(%v): %v", b.Blocks(), err)
+		}
+
+		if !x.Board().Equal(b) {
+			t.Fatalf("round trip of %v gives %v", b.Blocks(), x.Board().Blocks())
+		}
+		if x.CanMove() != b.CanMove() {
+			t.Fatalf("CanMove of %v: bitboard %v, board %v", b.Blocks(), x.CanMove(), b.CanMove())
+		}
+		if x.Highest() != b.Highest() {
+			t.Fatalf("Highest of %v: bitboard %d, board %d", b.Blocks(), x.Highest(), b.Highest())
+		}
+		if !reflect.DeepEqual(x.EmptyCells(), b.EmptyCells()) {
+			t.Fatalf("EmptyCells of %v: bitboard %v, board %v", b.Blocks(), x.EmptyCells(), b.EmptyCells())
+		}
+
+		for _, dir := range []Direction{Up, Down, Left, Right} {
+			xnext, xgained, xmoved := x.Move(dir)
+			next, gained, moved := b.Move(dir)
+			if !xnext.Board().Equal(next) || xgained != gained || xmoved != moved {
+				t.Fatalf(
+					"Move(%v) of %v: bitboard %v +%d %v, board %v +%d %v",
+					dir, b.Blocks(), xnext.Board().Blocks(), xgained, xmoved, next.Blocks(), gained, moved,
+				)
+			}
+		}
+
+		if empty := b.EmptyCells(); len(empty) > 0 {
+			c := empty[rng.Intn(len(empty))]
+			block := 2 << uint(rng.Intn(2))
+			if !x.Spawn(c, block).Board().Equal(b.Spawn(c, block)) {
+				t.Fatalf("Spawn(%v, %d) of %v differs", c, block, b.Blocks())
+			}
+		}
+	}
+}
+
+func TestBitboardMaxBlocksDontMerge(t *testing.T) {
+	b, _ := BoardOf([][]int{
+		{MaxBitboardBlock, MaxBitboardBlock, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+	})
+	x, err := BitboardOf(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, gained, moved := x.Move(Left)
+	if moved || gained != 0 || next != x {
+		t.Errorf("Move(left) = %v +%d %v, want the board unchanged", next.Board().Blocks(), gained, moved)
+	}
+	next, gained, moved = x.Move(Right)
+	want := [][]int{{0, 0, MaxBitboardBlock, MaxBitboardBlock}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}
+	if !moved || gained != 0 || !reflect.DeepEqual(next.Board().Blocks(), want) {
+		t.Errorf("Move(right) = %v +%d %v, want %v +0 true", next.Board().Blocks(), gained, moved, want)
+	}
+
+	big, _ := BoardOf([][]int{{MaxBitboardBlock * 2, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}})
+	if _, err := BitboardOf(big); err == nil {
+		t.Errorf("BitboardOf accepted a block larger than %d", MaxBitboardBlock)
+	}
+}
@@ -0,0 +1,259 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Cell struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+// Position is the common interface of the board engines: Board, which
+// supports every board size, and Bitboard, which is limited to 4x4 boards
+// but is much faster. Searches written against Position work with both.
+type Position interface {
+	Rows() int
+	Cols() int
+	Block(i int, j int) int
+	Highest() int
+	EmptyCells() []Cell
+	CanMove() bool
+
+	// Moved and Spawned are Move and Spawn returning a Position.
+	Moved(dir Direction) (next Position, gained int, moved bool)
+	Spawned(c Cell, block int) Position
+}
+
+// Board is an immutable matrix of blocks. Operations on a board never
+// modify it, they return a new board instead, so boards can be freely
+// shared and used to evaluate hypothetical moves.
+type Board struct {
+	rows  int
+	cols  int
+	cells []int // blocks in row-major order
+}
+
+// NewBoard returns an empty board.
+func NewBoard(rows int, cols int) Board {
+	return Board{
+		rows:  rows,
+		cols:  cols,
+		cells: make([]int, rows*cols),
+	}
+}
+
+// BoardOf returns a board with the given blocks, every row must have the
+// same length, and every block must be 0 (no block) or a power of two.
+func BoardOf(blocks [][]int) (Board, error) {
+	if len(blocks) == 0 || len(blocks[0]) == 0 {
+		return Board{}, errors.New("empty board")
+	}
+
+	b := NewBoard(len(blocks), len(blocks[0]))
+	for i, row := range blocks {
+		if len(row) != b.cols {
+			return Board{}, errors.New("rows of different length")
+		}
+		for j, block := range row {
+			if block < 0 || block == 1 || block&(block-1) != 0 {
+				return Board{}, fmt.Errorf("invalid block %d at (%d,%d)", block, i, j)
+			}
+			b.cells[i*b.cols+j] = block
+		}
+	}
+	return b, nil
+}
+
+func (b Board) Rows() int {
+	return b.rows
+}
+
+func (b Board) Cols() int {
+	return b.cols
+}
+
+// Block returns the block at (i,j), or -1 if the cell is outside the board.
+func (b Board) Block(i int, j int) int {
+	if i < 0 || i >= b.rows || j < 0 || j >= b.cols {
+		return -1
+	}
+	return b.cells[i*b.cols+j]
+}
+
+// Blocks returns a copy of the matrix of blocks.
+func (b Board) Blocks() [][]int {
+	blocks := make([][]int, b.rows)
+	for i := range blocks {
+		blocks[i] = append([]int(nil), b.cells[i*b.cols:(i+1)*b.cols]...)
+	}
+	return blocks
+}
+
+func (b Board) Equal(other Board) bool {
+	if b.rows != other.rows || b.cols != other.cols {
+		return false
+	}
+	for k, block := range b.cells {
+		if other.cells[k] != block {
+			return false
+		}
+	}
+	return true
+}
+
+// BlockCount returns the number of blocks on the board.
+func (b Board) BlockCount() int {
+	cnt := 0
+	for _, block := range b.cells {
+		if block != 0 {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// Highest returns the value of the largest block on the board.
+func (b Board) Highest() int {
+	highest := 0
+	for _, block := range b.cells {
+		highest = max(highest, block)
+	}
+	return highest
+}
+
+// Contains reports whether there is a block of the given value on the board.
+func (b Board) Contains(block int) bool {
+	for _, other := range b.cells {
+		if other == block {
+			return true
+		}
+	}
+	return false
+}
+
+func (b Board) EmptyCells() []Cell {
+	var empty []Cell
+	for k, block := range b.cells {
+		if block == 0 {
+			empty = append(empty, Cell{k / b.cols, k % b.cols})
+		}
+	}
+	return empty
+}
+
+// Spawn returns the board with a block placed on the cell,
+// which must be an empty cell of the board.
+func (b Board) Spawn(c Cell, block int) Board {
+	switch b.Block(c.Row, c.Col) {
+	case 0:
+	case -1:
+		panic(fmt.Sprintf("core: spawn on (%d,%d), outside the board", c.Row, c.Col))
+	default:
+		panic(fmt.Sprintf("core: spawn on a non-empty cell (%d,%d)", c.Row, c.Col))
+	}
+	next := Board{
+		rows:  b.rows,
+		cols:  b.cols,
+		cells: append([]int(nil), b.cells...),
+	}
+	next.cells[c.Row*b.cols+c.Col] = block
+	return next
+}
+
+// CanMove reports whether a move in any direction would move a block.
+func (b Board) CanMove() bool {
+	for i := 0; i < b.rows; i++ {
+		for j := 0; j < b.cols; j++ {
+			block := b.cells[i*b.cols+j]
+			if block == 0 ||
+				(j < b.cols-1 && b.cells[i*b.cols+j+1] == block) ||
+				(i < b.rows-1 && b.cells[(i+1)*b.cols+j] == block) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// index of the k-th cell of line l, counting from the edge
+// the blocks are pushed towards
+func (b Board) index(dir Direction, l int, k int) int {
+	switch dir {
+	case Right:
+		return l*b.cols + b.cols - 1 - k
+	case Left:
+		return l*b.cols + k
+	case Up:
+		return k*b.cols + l
+	default: // Down
+		return (b.rows-1-k)*b.cols + l
+	}
+}
+
+// cell at the index of b.cells
+func (b Board) cell(index int) Cell {
+	return Cell{Row: index / b.cols, Col: index % b.cols}
+}
+
+// Move pushes the blocks in the given direction. It returns the resulting
+// board, the points scored by merging blocks, and whether any block moved.
+func (b Board) Move(dir Direction) (next Board, gained int, moved bool) {
+	return b.move(dir, nil)
+}
+
+// move is Move which also calls track (if not nil) with the movement
+// of every block of the board
+func (b Board) move(dir Direction, track func(t Tile)) (next Board, gained int, moved bool) {
+	lines, length := b.rows, b.cols
+	if dir == Up || dir == Down {
+		lines, length = b.cols, b.rows
+	}
+
+	next = NewBoard(b.rows, b.cols)
+	for l := 0; l < lines; l++ {
+		// fence is the position of the next free cell of the line,
+		// the block right before it can be merged unless it already was
+		fence, mergeable := 0, false
+		for k := 0; k < length; k++ {
+			from := b.index(dir, l, k)
+			block := b.cells[from]
+			if block == 0 {
+				continue
+			}
+
+			if mergeable && next.cells[b.index(dir, l, fence-1)] == block {
+				to := b.index(dir, l, fence-1)
+				next.cells[to] = block << 1
+				gained += block << 1
+				mergeable, moved = false, true
+				if track != nil {
+					track(Tile{From: b.cell(from), To: b.cell(to), Value: block, Merged: true})
+				}
+				continue
+			}
+
+			to := b.index(dir, l, fence)
+			next.cells[to] = block
+			if fence != k {
+				moved = true
+			}
+			if track != nil {
+				track(Tile{From: b.cell(from), To: b.cell(to), Value: block})
+			}
+			fence++
+			mergeable = true
+		}
+	}
+
+	return next, gained, moved
+}
+
+func (b Board) Moved(dir Direction) (Position, int, bool) {
+	return b.Move(dir)
+}
+
+func (b Board) Spawned(c Cell, block int) Position {
+	return b.Spawn(c, block)
+}
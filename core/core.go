@@ -1,6 +1,7 @@
 package core
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -13,16 +14,19 @@ import (
 //		0 is a special value meaning "no block"
 
 const (
-	MinSize = 4
-	MaxSize = 5
+	MinSize = 2
+	MaxSize = 16
 
-	MaxBlock       = 8192
-	MaxBlockDigits = 4
+	// largest block on a classic board, larger boards in endless mode
+	// can go beyond it
+	MaxBlock       = 131072
+	MaxBlockDigits = 6
 
 	MinTarget = 64
-	MaxTarget = MaxBlock
+	MaxTarget = 8192
 
-	blockFourProbability float64 = 0.15
+	// probability that a spawned block is 4 rather than 2
+	BlockFourProbability float64 = 0.15
 )
 
 type Direction int
@@ -34,14 +38,69 @@ const (
 	Down
 )
 
+var directionNames = [...]string{
+	Right: "right",
+	Left:  "left",
+	Up:    "up",
+	Down:  "down",
+}
+
+func (d Direction) String() string {
+	if d < Right || d > Down {
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+	return directionNames[d]
+}
+
+// ParseDirection is the inverse of Direction.String.
+func ParseDirection(s string) (Direction, error) {
+	for d, name := range directionNames {
+		if s == name {
+			return Direction(d), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid direction: %q", s)
+}
+
+func (d Direction) MarshalText() ([]byte, error) {
+	if d < Right || d > Down {
+		return nil, fmt.Errorf("invalid direction: %d", int(d))
+	}
+	return []byte(d.String()), nil
+}
+
+func (d *Direction) UnmarshalText(text []byte) error {
+	dir, err := ParseDirection(string(text))
+	if err != nil {
+		return err
+	}
+	*d = dir
+	return nil
+}
+
 type Outcome int
 
 const (
-	Continue Outcome = iota
-	GameOver
-	GameOverWin
+	Continue    Outcome = iota
+	GameOver            // no moves left
+	GameOverWin         // target reached
+	GameQuit            // player quit
 )
 
+var outcomeNames = [...]string{
+	Continue:    "continue",
+	GameOver:    "gameover",
+	GameOverWin: "win",
+	GameQuit:    "quit",
+}
+
+func (o Outcome) String() string {
+	if o < Continue || o > GameQuit {
+		return fmt.Sprintf("Outcome(%d)", int(o))
+	}
+	return outcomeNames[o]
+}
+
 type Phase int
 
 const (
@@ -50,65 +109,194 @@ const (
 	Finished
 )
 
-type stableState struct {
-	board    [][]int // matrix of cells
-	score    int     // player's score
-	blockCnt int     // number of blocks on the board
+var phaseNames = [...]string{
+	NotStarted:  "notstarted",
+	NotFinished: "notfinished",
+	Finished:    "finished",
 }
 
-// assumes size is in limits
-func newInitialState(size int) *stableState {
-	// all cells are initially empty (aka all blocks are 0)
-	board := make([][]int, size)
-	for i := 0; i < size; i++ {
-		board[i] = make([]int, size)
+func (p Phase) String() string {
+	if p < NotStarted || p > Finished {
+		return fmt.Sprintf("Phase(%d)", int(p))
+	}
+	return phaseNames[p]
+}
+
+// ParsePhase is the inverse of Phase.String.
+func ParsePhase(s string) (Phase, error) {
+	for p, name := range phaseNames {
+		if s == name {
+			return Phase(p), nil
+		}
 	}
+	return 0, fmt.Errorf("invalid phase: %q", s)
+}
+
+func (p Phase) MarshalText() ([]byte, error) {
+	if p < NotStarted || p > Finished {
+		return nil, fmt.Errorf("invalid phase: %d", int(p))
+	}
+	return []byte(p.String()), nil
+}
+
+func (p *Phase) UnmarshalText(text []byte) error {
+	phase, err := ParsePhase(string(text))
+	if err != nil {
+		return err
+	}
+	*p = phase
+	return nil
+}
+
+// UnmarshalJSON also accepts the numbers phases were saved as
+// before they had names.
+func (p *Phase) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		if Phase(n) < NotStarted || Phase(n) > Finished {
+			return fmt.Errorf("invalid phase: %d", n)
+		}
+		*p = Phase(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return p.UnmarshalText([]byte(s))
+}
 
+type stableState struct {
+	board    Board // matrix of cells
+	score    int   // player's score
+	blockCnt int   // number of blocks on the board
+	merges   int   // number of merged pairs of blocks
+}
+
+// assumes rows and cols are in limits
+func newInitialState(rows int, cols int) *stableState {
+	// all cells are initially empty (aka all blocks are 0)
 	return &stableState{
-		board:    board,
+		board:    NewBoard(rows, cols),
 		score:    0,
 		blockCnt: 0,
 	}
 }
 
-// assumes board size of both states are equal, since this
-// function is only used inside the package
+// boards are immutable, so sharing the board of the other state is
+// as good as copying it
 func (s *stableState) deepCopyFrom(other *stableState) {
-	for i, row := range other.board {
-		for j, block := range row {
-			s.board[i][j] = block
-		}
-	}
-	s.score, s.blockCnt = other.score, other.blockCnt
+	*s = *other
 }
 
 type Game struct {
 	Player string // player's name
 	Target int    // end-game block
-	Size   int    // size of the board
+	Rows   int    // number of rows of the board
+	Cols   int    // number of columns of the board
 	Phase  Phase  // game phase
 
-	stableState                  // embedded current state
-	prevStableState *stableState // previous stable state
-	scratchState    *stableState // scratch state
-
-	canUndo       bool
-	undosLeft     int
-	anyBlockMoved bool
-	rng           *rand.Rand // random number generator
+	stableState                // embedded current state
+	undoStack   []*stableState // states before the moves that can be undone
+	redoStack   []redoEntry    // undone moves that can be redone
+	freeStates  []*stableState // states that can be reused
+
+	id        string // unique to the game, unlike the seed which can be chosen
+	undoMode  UndoMode
+	undos     int // undos the game started with
+	undosLeft int
+	endless   bool       // keep going after the target is reached
+	quit      bool       // the player quit the game
+	start     []Spawn    // blocks spawned at the start of the game
+	history   []Move     // moves that led to the current state
+	src       *rngSource // source of rng, tracks its position
+	rng       *rand.Rand // random number generator
+	forced    *Spawn     // block spawned by the next push instead of a random one
+
+	// play time, the clock is stopped while the game is finished
+	elapsed time.Duration // play time up to since
+	since   time.Time     // when the clock was last started
+
+	stats      Stats
+	lastAction time.Time  // when the player last pushed, undid or redid
+	transition Transition // how the board changed in the last push
+
+	observers        []subscription
+	lastSubscription int     // id of the last subscription
+	events           []Event // events yet to be delivered to the observers
+	dispatching      bool
+
+	// if set, the game is saved after every successful push or undo,
+	// and the save is removed once the game is finished
+	Autosave bool
 }
 
 func NewGame(player string, size int, target int, undos int) (*Game, error) {
+	return NewGameWithSeed(player, size, target, undos, time.Now().UnixNano())
+}
+
+// NewGameWithSeed creates a game whose random block spawns are fully
+// determined by the seed, i.e. the same seed and the same sequence of
+// moves always produce the same game.
+func NewGameWithSeed(player string, size int, target int, undos int, seed int64) (*Game, error) {
+	return NewRectGame(player, size, size, target, undos, seed)
+}
+
+// NewRectGame creates a game on a board with independent number
+// of rows and columns, spawns are determined by the seed.
+func NewRectGame(player string, rows int, cols int, target int, undos int, seed int64) (*Game, error) {
+	game, err := newEmptyGame(player, rows, cols, target, undos, newRNGSource(seed, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	// spawn two blocks at the start of the game
+	game.start = append(game.start, game.spawn(), game.spawn())
+
+	return game, nil
+}
+
+// Restart replaces the game with a new one, started with the given seed,
+// keeping the player, the board size, the target and the undo rules.
+func (g *Game) Restart(seed int64) {
+	// the parameters were validated when the game was created
+	fresh, _ := NewRectGame(g.Player, g.Rows, g.Cols, g.Target, g.undos, seed)
+	fresh.SetUndoMode(g.undoMode)
+	fresh.Autosave = g.Autosave
+	fresh.observers, fresh.lastSubscription = g.observers, g.lastSubscription
+	fresh.events, fresh.dispatching = g.events, g.dispatching
+	phase := g.Phase
+	*g = *fresh
+	if phase != NotStarted {
+		g.emit(PhaseChanged{From: phase, To: NotStarted})
+	}
+	g.autosave()
+	g.dispatch()
+}
+
+// validates params and creates a game with an empty board
+func newEmptyGame(player string, rows int, cols int, target int, undos int, src *rngSource) (*Game, error) {
 	// param validation
 	//
 	if player == "" {
 		return nil, errors.New("player name cannot be empty")
 	}
 
-	if size < MinSize || size > MaxSize {
+	if rows < MinSize || rows > MaxSize {
 		errmsg := fmt.Sprintf(
-			"invalid size: %d, allowed range [%d, %d]",
-			size,
+			"invalid number of rows: %d, allowed range [%d, %d]",
+			rows,
+			MinSize,
+			MaxSize,
+		)
+		return nil, errors.New(errmsg)
+	}
+
+	if cols < MinSize || cols > MaxSize {
+		errmsg := fmt.Sprintf(
+			"invalid number of columns: %d, allowed range [%d, %d]",
+			cols,
 			MinSize,
 			MaxSize,
 		)
@@ -129,363 +317,313 @@ func NewGame(player string, size int, target int, undos int) (*Game, error) {
 
 	// create a new game
 	game := &Game{
-		Player:          player,
-		Target:          target,
-		Size:            size,
-		Phase:           NotStarted,
-		stableState:     *newInitialState(size),
-		prevStableState: newInitialState(size),
-		scratchState:    newInitialState(size),
-		canUndo:         false,
-		undosLeft:       undos,
-		anyBlockMoved:   false,
-		rng:             rand.New(rand.NewSource(time.Now().UnixNano())),
+		Player:      player,
+		Target:      target,
+		Rows:        rows,
+		Cols:        cols,
+		Phase:       NotStarted,
+		stableState: *newInitialState(rows, cols),
+		id:          newGameID(),
+		undoMode:    ClassicUndo,
+		undos:       undos,
+		undosLeft:   undos,
+		src:         src,
+		rng:         rand.New(src),
+		since:       time.Now(),
+		stats:       newStats(),
+		lastAction:  time.Now(),
 	}
 
-	// spawn two blocks at the start of the game
-	game.spawn()
-	game.spawn()
-
 	return game, nil
 }
 
 func (g *Game) randBlock() int {
-	if ptrue(blockFourProbability, g.rng) {
+	if ptrue(BlockFourProbability, g.rng) {
 		return 4
 	}
 	return 2
 }
 
-func (g *Game) spawn() {
-	if g.blockCnt == g.Size*g.Size {
-		return
+// returns the spawned block, or a zero Spawn if the board is full
+func (g *Game) spawn() Spawn {
+	if g.blockCnt == g.Rows*g.Cols {
+		return Spawn{}
 	}
 
+	if forced := g.forced; forced != nil {
+		g.forced = nil
+		if g.place(*forced) == nil {
+			return *forced
+		}
+	}
+
+	var spawned Spawn
 	for {
-		n := g.rng.Intn(g.Size * g.Size)
-		i, j := n/g.Size, n%g.Size
-		if g.board[i][j] == 0 {
-			g.board[i][j] = g.randBlock()
+		n := g.rng.Intn(g.Rows * g.Cols)
+		cell := Cell{n / g.Cols, n % g.Cols}
+		if g.board.Block(cell.Row, cell.Col) == 0 {
+			spawned = Spawn{Cell: cell, Value: g.randBlock()}
 			break
 		}
 	}
 
+	g.board = g.board.Spawn(spawned.Cell, spawned.Value)
 	g.blockCnt++
+	return spawned
 }
 
-func (g *Game) canMergeAnyNeighbor(i int, j int, blkval int) bool {
-	// left neighbor
-	if j > 0 && g.board[i][j-1] == blkval {
-		return true
-	}
-	// right neighbor
-	if j < g.Size-1 && g.board[i][j+1] == blkval {
-		return true
-	}
-	// up neighbor
-	if i > 0 && g.board[i-1][j] == blkval {
-		return true
+// places a block on an empty cell
+func (g *Game) place(s Spawn) error {
+	if g.Block(s.Row, s.Col) != 0 {
+		return fmt.Errorf("cannot place block at (%d,%d)", s.Row, s.Col)
 	}
-	// down neighbor
-	if i < g.Size-1 && g.board[i+1][j] == blkval {
-		return true
+	if s.Value != 2 && s.Value != 4 {
+		return fmt.Errorf("invalid spawned block %d", s.Value)
 	}
-
-	return false
+	g.board = g.board.Spawn(s.Cell, s.Value)
+	g.blockCnt++
+	return nil
 }
 
 // note: it is important that this operation be indepotent
 // and that it works in every game phase
 func (g *Game) calcOutcome() Outcome {
-	canContinue := false
-
-	for i, row := range g.board {
-		for j, blkval := range row {
-			if blkval == g.Target {
-				g.Phase = Finished
-				return GameOverWin
-			}
-			if !canContinue &&
-				(blkval == 0 || g.canMergeAnyNeighbor(i, j, blkval)) {
-
-				canContinue = true
-			}
+	finished := g.Phase == Finished
+	if !g.endless && g.board.Contains(g.Target) {
+		g.setPhase(Finished)
+		if !finished {
+			g.emit(GameWon{Score: g.score, Block: g.Target})
 		}
+		return GameOverWin
 	}
 
-	if canContinue {
-		g.Phase = NotFinished
+	if g.board.CanMove() {
+		g.setPhase(NotFinished)
 		return Continue
 	}
 
-	g.Phase = Finished
+	g.setPhase(Finished)
+	if !finished {
+		g.emit(GameLost{Score: g.score, Highest: g.board.Highest()})
+	}
 	return GameOver
 }
 
-func (g *Game) copyToPrevState() {
-	if g.undosLeft == 0 {
-		return
+// stops the clock when the game finishes, and restarts it
+// when a won game is continued
+func (g *Game) setPhase(phase Phase) {
+	switch {
+	case phase == Finished && g.Phase != Finished:
+		g.elapsed += time.Since(g.since)
+	case phase != Finished && g.Phase == Finished:
+		g.since = time.Now()
 	}
-	g.prevStableState.deepCopyFrom(&g.stableState)
-}
-
-func (g *Game) rollbackPrevState() {
-	if g.undosLeft == 0 {
-		return
+	if phase != g.Phase {
+		g.emit(PhaseChanged{From: g.Phase, To: phase})
 	}
-	g.prevStableState.deepCopyFrom(g.scratchState)
+	g.Phase = phase
 }
 
-func (g *Game) commitPrevState() {
-	if g.undosLeft == 0 {
-		return
+func (g *Game) Push(dir Direction) Outcome {
+	if g.quit {
+		return GameQuit
 	}
-	g.scratchState.deepCopyFrom(g.prevStableState)
-}
-
-func (g *Game) _pushRight(lcell int, rcell int, fence int, row []int) {
-	if rcell != fence && row[rcell] != 0 {
-		row[fence], row[rcell] = row[rcell], 0
-		g.anyBlockMoved = true
+	defer g.dispatch()
+	if g.Phase == Finished {
+		return g.calcOutcome()
 	}
 
-	if row[fence] == 0 || row[lcell] == 0 {
-		if row[lcell] != 0 {
-			g.anyBlockMoved = true
-		}
-		row[fence] += row[lcell]
-		row[lcell] = 0
-		return
+	score := g.score
+	if !g.move(dir) {
+		g.stats.WastedPushes++
+		return Continue
 	}
 
-	if row[fence] == row[lcell] {
-		row[fence] <<= 1
-		row[lcell] = 0
-		g.score += row[fence]
-		g.blockCnt--
-		g.anyBlockMoved = true
-		return
-	}
+	g.stats.addMove(dir, time.Since(g.lastAction))
+	g.lastAction = time.Now()
 
-	if lcell != fence-1 {
-		row[fence-1], row[lcell] = row[lcell], 0
-		g.anyBlockMoved = true
-	}
+	spawned := g.spawn()
+	g.transition.Spawn = spawned
+	g.emitTransition(g.transition, score)
+	g.history = append(g.history, Move{Dir: dir, Spawn: spawned})
+	outcome := g.calcOutcome()
+	g.autosave()
+	return outcome
 }
 
-func (g *Game) _pushLeft(lcell int, rcell int, fence int, row []int) {
-	if lcell != fence && row[lcell] != 0 {
-		row[fence], row[lcell] = row[lcell], 0
-		g.anyBlockMoved = true
-	}
-
-	if row[fence] == 0 || row[rcell] == 0 {
-		if row[rcell] != 0 {
-			g.anyBlockMoved = true
+// pushes the blocks without spawning a new one,
+// returns false (and leaves the state untouched) if no block moved
+func (g *Game) move(dir Direction) bool {
+	at := g.Duration()
+	var tiles []Tile
+	next, gained, moved := g.board.move(dir, func(t Tile) {
+		tiles = append(tiles, t)
+		if t.Merged {
+			g.stats.addMerge(t.Value<<1, at)
 		}
-		row[fence] += row[rcell]
-		row[rcell] = 0
-		return
+	})
+	if !moved {
+		return false
 	}
+	g.transition = Transition{Dir: dir, Tiles: tiles}
 
-	if row[fence] == row[rcell] {
-		row[fence] <<= 1
-		row[rcell] = 0
-		g.score += row[fence]
-		g.blockCnt--
-		g.anyBlockMoved = true
-		return
+	if g.undoDepth() > 0 {
+		prev := g.allocState()
+		prev.deepCopyFrom(&g.stableState)
+		g.pushUndoState(prev)
 	}
+	g.clearRedoStack()
 
-	if rcell != fence+1 {
-		row[fence+1], row[rcell] = row[rcell], 0
-		g.anyBlockMoved = true
-	}
-}
+	// every merge of two blocks leaves one block less on the board
+	g.merges += g.blockCnt - next.BlockCount()
 
-func (g *Game) _pushUp(ucell int, dcell int, fence int, col int) {
-	if ucell != fence && g.board[ucell][col] != 0 {
-		g.board[fence][col], g.board[ucell][col] = g.board[ucell][col], 0
-		g.anyBlockMoved = true
-	}
+	g.board = next
+	g.score += gained
+	g.blockCnt = next.BlockCount()
+	return true
+}
 
-	if g.board[fence][col] == 0 || g.board[dcell][col] == 0 {
-		if g.board[dcell][col] != 0 {
-			g.anyBlockMoved = true
-		}
-		g.board[fence][col] += g.board[dcell][col]
-		g.board[dcell][col] = 0
+// autosave is best-effort, a failure to save must not interrupt the game
+func (g *Game) autosave() {
+	if !g.Autosave {
 		return
 	}
-
-	if g.board[fence][col] == g.board[dcell][col] {
-		g.board[fence][col] <<= 1
-		g.board[dcell][col] = 0
-		g.score += g.board[fence][col]
-		g.blockCnt--
-		g.anyBlockMoved = true
+	if g.Phase == Finished {
+		RemoveSave()
 		return
 	}
+	g.Save()
+}
 
-	if dcell != fence+1 {
-		g.board[fence+1][col], g.board[dcell][col] = g.board[dcell][col], 0
-		g.anyBlockMoved = true
+// KeepGoing continues a won game, it then lasts until no moves remain.
+func (g *Game) KeepGoing() bool {
+	defer g.dispatch()
+	if g.Outcome() != GameOverWin {
+		return false
 	}
+	g.endless = true
+	g.calcOutcome()
+	g.autosave()
+	return true
 }
 
-func (g *Game) _pushDown(ucell int, dcell int, fence int, col int) {
-	if dcell != fence && g.board[dcell][col] != 0 {
-		g.board[fence][col], g.board[dcell][col] = g.board[dcell][col], 0
-		g.anyBlockMoved = true
-	}
+// Endless reports whether the game was continued after it was won.
+func (g *Game) Endless() bool {
+	return g.endless
+}
 
-	if g.board[fence][col] == 0 || g.board[ucell][col] == 0 {
-		if g.board[ucell][col] != 0 {
-			g.anyBlockMoved = true
-		}
-		g.board[fence][col] += g.board[ucell][col]
-		g.board[ucell][col] = 0
-		return
-	}
+// HighestBlock returns the value of the largest block on the board.
+func (g *Game) HighestBlock() int {
+	return g.board.Highest()
+}
+
+// Clone returns an independent copy of the game, which can be played
+// without affecting the original: the copy has its own random number
+// generator (at the same position), undo budget and history, and it
+// is never saved.
+func (g *Game) Clone() *Game {
+	c := *g
+	c.stableState = *newInitialState(g.Rows, g.Cols)
+	c.stableState.deepCopyFrom(&g.stableState)
+
+	c.undoStack, c.redoStack, c.freeStates = nil, nil, nil
+	for _, s := range g.undoStack {
+		state := newInitialState(g.Rows, g.Cols)
+		state.deepCopyFrom(s)
+		c.undoStack = append(c.undoStack, state)
+	}
+	for _, entry := range g.redoStack {
+		state := newInitialState(g.Rows, g.Cols)
+		state.deepCopyFrom(entry.state)
+		c.redoStack = append(c.redoStack, redoEntry{state: state, move: entry.move})
+	}
+
+	c.stats = g.stats.clone()
+	c.start = append([]Spawn(nil), g.start...)
+	c.history = append([]Move(nil), g.history...)
+	c.src = newRNGSource(g.src.seed, g.src.draws)
+	c.rng = rand.New(c.src)
+	c.Autosave = false
+	c.observers, c.events, c.dispatching = nil, nil, false
+	return &c
+}
 
-	if g.board[fence][col] == g.board[ucell][col] {
-		g.board[fence][col] <<= 1
-		g.board[ucell][col] = 0
-		g.score += g.board[fence][col]
-		g.blockCnt--
-		g.anyBlockMoved = true
+// Quit ends the game on behalf of the player. The last saved state of an
+// unfinished game is kept, so that it can be resumed.
+func (g *Game) Quit() {
+	if g.quit {
 		return
 	}
-
-	if ucell != fence-1 {
-		g.board[fence-1][col], g.board[ucell][col] = g.board[ucell][col], 0
-		g.anyBlockMoved = true
+	if g.Phase != Finished {
+		g.elapsed += time.Since(g.since)
 	}
+	g.quit = true
 }
 
-func (g *Game) pushRight() {
-	for _, row := range g.board {
-		fence := g.Size - 1
-		for fence > 0 {
-			rcell := fence
-			for rcell > 1 && row[rcell] == 0 {
-				rcell--
-			}
-			lcell := rcell - 1
-			for lcell > 0 && row[lcell] == 0 {
-				lcell--
-			}
-			g._pushRight(lcell, rcell, fence, row)
-			fence--
-		}
+// Outcome reports the outcome of the game in its current state.
+func (g *Game) Outcome() Outcome {
+	if g.quit {
+		return GameQuit
 	}
-}
-
-func (g *Game) pushLeft() {
-	for _, row := range g.board {
-		fence := 0
-		for fence < g.Size-1 {
-			lcell := fence
-			for lcell < g.Size-2 && row[lcell] == 0 {
-				lcell++
-			}
-			rcell := lcell + 1
-			for rcell < g.Size-1 && row[rcell] == 0 {
-				rcell++
-			}
-			g._pushLeft(lcell, rcell, fence, row)
-			fence++
-		}
+	if g.Phase == NotStarted {
+		return Continue
 	}
+	defer g.dispatch()
+	return g.calcOutcome()
 }
 
-func (g *Game) pushUp() {
-	for col := 0; col < g.Size; col++ {
-		fence := 0
-		for fence < g.Size-1 {
-			ucell := fence
-			for ucell < g.Size-2 && g.board[ucell][col] == 0 {
-				ucell++
-			}
-			dcell := ucell + 1
-			for dcell < g.Size-1 && g.board[dcell][col] == 0 {
-				dcell++
-			}
-			g._pushUp(ucell, dcell, fence, col)
-			fence++
-		}
-	}
+func (g *Game) Score() int {
+	return g.score
 }
 
-func (g *Game) pushDown() {
-	for col := 0; col < g.Size; col++ {
-		fence := g.Size - 1
-		for fence > 0 {
-			dcell := fence
-			for dcell > 1 && g.board[dcell][col] == 0 {
-				dcell--
-			}
-			ucell := dcell - 1
-			for ucell > 0 && g.board[ucell][col] == 0 {
-				ucell--
-			}
-			g._pushDown(ucell, dcell, fence, col)
-			fence--
-		}
-	}
+// Merges returns the number of times two blocks were merged.
+func (g *Game) Merges() int {
+	return g.merges
 }
 
-func (g *Game) Push(dir Direction) Outcome {
-	if g.Phase == Finished {
-		return g.calcOutcome()
-	}
-
-	g.copyToPrevState()
-
-	switch dir {
-	case Right:
-		g.pushRight()
-	case Left:
-		g.pushLeft()
-	case Up:
-		g.pushUp()
-	case Down:
-		g.pushDown()
-	}
-
-	if !g.anyBlockMoved {
-		g.rollbackPrevState()
-		return Continue
-	}
+func (g *Game) Seed() int64 {
+	return g.src.seed
+}
 
-	g.anyBlockMoved = false
-	g.canUndo = true
-	g.spawn()
-	g.commitPrevState()
-	return g.calcOutcome()
+// Moves returns the number of moves that led to the current state.
+func (g *Game) Moves() int {
+	return len(g.history)
 }
 
-func (g *Game) Undo() bool {
-	if g.Phase == Finished || g.undosLeft == 0 || !g.canUndo {
-		return false
-	}
-	g.stableState.deepCopyFrom(g.prevStableState)
-	g.undosLeft--
-	g.canUndo = false // two consecutive undos are not allowed
-	return true
+// History returns the moves that led to the current state,
+// undone moves are not part of the history.
+func (g *Game) History() []Move {
+	return append([]Move(nil), g.history...)
 }
 
-func (g *Game) Score() int {
-	return g.score
+// Undos returns the number of undos the game started with.
+func (g *Game) Undos() int {
+	return g.undos
 }
 
 func (g *Game) UndosLeft() int {
 	return g.undosLeft
 }
 
-func (g *Game) Block(i int, j int) int {
-	if i < 0 || i >= g.Size || j < 0 || j >= g.Size {
-		return -1
+func (g *Game) UndosUsed() int {
+	return g.undos - g.undosLeft
+}
+
+// Duration returns the time spent playing the game, including
+// the time spent before it was saved and resumed.
+func (g *Game) Duration() time.Duration {
+	if g.Phase == Finished || g.quit {
+		return g.elapsed
 	}
-	return g.board[i][j]
+	return g.elapsed + time.Since(g.since)
+}
+
+func (g *Game) Block(i int, j int) int {
+	return g.board.Block(i, j)
+}
+
+// Board returns the current board, since boards are immutable
+// it can be used to explore moves without affecting the game.
+func (g *Game) Board() Board {
+	return g.board
 }
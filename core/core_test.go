@@ -0,0 +1,35 @@
+package core
+
+import "testing"
+
+func TestCloneLeavesGameUntouched(t *testing.T) {
+	g, _ := NewGameWithSeed("p", 4, 2048, 3, 1)
+	g.SetUndoMode(UnlimitedUndo)
+	playMoves(t, g, 4)
+	g.Undo()
+	board, score, moves := g.Board(), g.Score(), g.Moves()
+	draws, undosLeft := g.src.draws, g.UndosLeft()
+
+	c := g.Clone()
+	boards, scores := playMoves(t, c, 3)
+	if !c.Undo() || !c.Undo() {
+		t.Fatal("undo in the clone failed")
+	}
+	if c.Undo() {
+		t.Error("clone allowed more undos than the game had left")
+	}
+
+	checkState(t, g, board, score, moves)
+	if g.src.draws != draws {
+		t.Errorf("random draws = %d after playing the clone, want %d", g.src.draws, draws)
+	}
+	if g.UndosLeft() != undosLeft || !g.CanRedo() {
+		t.Errorf("undos left = %d, redo %v after playing the clone, want %d, true", g.UndosLeft(), g.CanRedo(), undosLeft)
+	}
+
+	// the game spawns the blocks the clone did
+	for i := 1; i < len(boards); i++ {
+		pushAny(t, g)
+		checkState(t, g, boards[i], scores[i], moves+i)
+	}
+}
@@ -0,0 +1,147 @@
+package core
+
+// Event is something that happened in a game, one of the event types
+// below. Observers subscribed to a game receive its events in the order
+// they happened, once the operation that caused them has completed.
+type Event interface {
+	// Name returns the name of the type of the event, such as "TileMoved".
+	Name() string
+}
+
+// TileMoved is sent for every block that moved to another cell in a push,
+// without merging.
+type TileMoved struct {
+	Tile
+}
+
+// TileMerged is sent for every block that merged into another one in a
+// push, Block is the value of the merged block.
+type TileMerged struct {
+	Tile
+	Block int `json:"block"`
+}
+
+// TileSpawned is sent for the block spawned after a push.
+type TileSpawned struct {
+	Spawn
+}
+
+// ScoreChanged is sent when a push, redo or undo changes the score.
+type ScoreChanged struct {
+	Score int `json:"score"`
+	Delta int `json:"delta"` // negative after an undo
+}
+
+// UndoApplied is sent when a move is undone. A redone move is sent
+// as the tile events of the push it repeats.
+type UndoApplied struct {
+	Move      Move `json:"move"` // the undone move
+	UndosLeft int  `json:"undosLeft"`
+}
+
+// PhaseChanged is sent when the game changes its phase.
+type PhaseChanged struct {
+	From Phase `json:"from"`
+	To   Phase `json:"to"`
+}
+
+// GameWon is sent when the target block is reached.
+type GameWon struct {
+	Score int `json:"score"`
+	Block int `json:"block"` // the target
+}
+
+// GameLost is sent when no moves remain.
+type GameLost struct {
+	Score   int `json:"score"`
+	Highest int `json:"highest"` // the largest block on the board
+}
+
+func (TileMoved) Name() string    { return "TileMoved" }
+func (TileMerged) Name() string   { return "TileMerged" }
+func (TileSpawned) Name() string  { return "TileSpawned" }
+func (ScoreChanged) Name() string { return "ScoreChanged" }
+func (UndoApplied) Name() string  { return "UndoApplied" }
+func (PhaseChanged) Name() string { return "PhaseChanged" }
+func (GameWon) Name() string      { return "GameWon" }
+func (GameLost) Name() string     { return "GameLost" }
+
+// Observer receives the events of the games it is subscribed to.
+type Observer interface {
+	Notify(e Event)
+}
+
+// ObserverFunc adapts a function to the Observer interface.
+type ObserverFunc func(e Event)
+
+func (f ObserverFunc) Notify(e Event) {
+	f(e)
+}
+
+type subscription struct {
+	id       int
+	observer Observer
+}
+
+// Subscribe adds an observer of the events of the game, it stays
+// subscribed across restarts. The returned function unsubscribes it.
+func (g *Game) Subscribe(o Observer) (unsubscribe func()) {
+	g.lastSubscription++
+	id := g.lastSubscription
+	g.observers = append(g.observers, subscription{id: id, observer: o})
+	return func() {
+		for i, s := range g.observers {
+			if s.id == id {
+				g.observers = append(g.observers[:i:i], g.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// queues an event, if anyone is listening
+func (g *Game) emit(e Event) {
+	if len(g.observers) > 0 {
+		g.events = append(g.events, e)
+	}
+}
+
+// queues the events of the transition of the board, score is the score
+// before it
+func (g *Game) emitTransition(tr Transition, score int) {
+	for _, t := range tr.Tiles {
+		switch {
+		case t.Merged:
+			g.emit(TileMerged{Tile: t, Block: t.Value << 1})
+		case t.Moved():
+			g.emit(TileMoved{Tile: t})
+		}
+	}
+	if g.score != score {
+		g.emit(ScoreChanged{Score: g.score, Delta: g.score - score})
+	}
+	if tr.Spawn.Value != 0 {
+		g.emit(TileSpawned{Spawn: tr.Spawn})
+	}
+}
+
+// delivers the queued events, observers may act on the game
+// in the meantime, their events are delivered after these
+func (g *Game) dispatch() {
+	if g.dispatching {
+		return
+	}
+	g.dispatching = true
+	defer func() { g.dispatching = false }()
+
+	for len(g.events) > 0 {
+		events := g.events
+		g.events = nil
+		observers := append([]subscription(nil), g.observers...)
+		for _, e := range events {
+			for _, s := range observers {
+				s.observer.Notify(e)
+			}
+		}
+	}
+}
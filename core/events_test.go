@@ -0,0 +1,205 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// starts a game on the given board, the first push spawns the given block
+func eventGame(t *testing.T, blocks [][]int, spawn Spawn) *Game {
+	t.Helper()
+	g, _ := NewGameWithSeed("p", len(blocks), 2048, 2, 1)
+	b, err := BoardOf(blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.board, g.blockCnt = b, b.BlockCount()
+	g.forced = &spawn
+	return g
+}
+
+// subscribes an observer that records the events of the game
+func record(g *Game) *[]Event {
+	var events []Event
+	g.Subscribe(ObserverFunc(func(e Event) {
+		events = append(events, e)
+	}))
+	return &events
+}
+
+func checkEvents(t *testing.T, got []Event, want []Event) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+var (
+	mergeBoard = [][]int{
+		{2, 2, 0, 4},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+	}
+	mergeSpawn  = Spawn{Cell: Cell{3, 3}, Value: 2}
+	mergeEvents = []Event{
+		TileMerged{Tile: Tile{From: Cell{0, 1}, To: Cell{0, 0}, Value: 2, Merged: true}, Block: 4},
+		TileMoved{Tile: Tile{From: Cell{0, 3}, To: Cell{0, 1}, Value: 4}},
+		ScoreChanged{Score: 4, Delta: 4},
+		TileSpawned{Spawn: mergeSpawn},
+	}
+)
+
+// returns the events of a push of mergeBoard to the left, followed by more
+func mergeAnd(more ...Event) []Event {
+	return append(append([]Event(nil), mergeEvents...), more...)
+}
+
+func TestEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks [][]int
+		spawn  Spawn
+		play   func(g *Game)
+		want   []Event
+	}{
+		{
+			name:   "push",
+			blocks: mergeBoard,
+			spawn:  mergeSpawn,
+			play:   func(g *Game) { g.Push(Left) },
+			want:   mergeAnd(PhaseChanged{From: NotStarted, To: NotFinished}),
+		},
+		{
+			name:   "push that moves nothing",
+			blocks: mergeBoard,
+			spawn:  mergeSpawn,
+			play:   func(g *Game) { g.Push(Up) },
+			want:   nil,
+		},
+		{
+			name:   "undo and redo",
+			blocks: mergeBoard,
+			spawn:  mergeSpawn,
+			play: func(g *Game) {
+				g.Push(Left)
+				g.Undo()
+				g.Redo()
+			},
+			want: append(
+				mergeAnd(
+					PhaseChanged{From: NotStarted, To: NotFinished},
+					UndoApplied{Move: Move{Dir: Left, Spawn: mergeSpawn}, UndosLeft: 1},
+					ScoreChanged{Score: 0, Delta: -4},
+				),
+				mergeEvents...,
+			),
+		},
+		{
+			name: "won",
+			blocks: [][]int{
+				{1024, 1024, 0, 0},
+				{0, 0, 0, 0},
+				{0, 0, 0, 0},
+				{0, 0, 0, 0},
+			},
+			spawn: Spawn{Cell: Cell{3, 3}, Value: 4},
+			play:  func(g *Game) { g.Push(Left) },
+			want: []Event{
+				TileMerged{Tile: Tile{From: Cell{0, 1}, To: Cell{0, 0}, Value: 1024, Merged: true}, Block: 2048},
+				ScoreChanged{Score: 2048, Delta: 2048},
+				TileSpawned{Spawn: Spawn{Cell: Cell{3, 3}, Value: 4}},
+				PhaseChanged{From: NotStarted, To: Finished},
+				GameWon{Score: 2048, Block: 2048},
+			},
+		},
+		{
+			name: "lost",
+			blocks: [][]int{
+				{2, 4, 8, 0},
+				{2, 8, 16, 32},
+				{8, 16, 32, 64},
+				{16, 32, 64, 128},
+			},
+			spawn: Spawn{Cell: Cell{0, 0}, Value: 4},
+			play:  func(g *Game) { g.Push(Right) },
+			want: []Event{
+				TileMoved{Tile: Tile{From: Cell{0, 2}, To: Cell{0, 3}, Value: 8}},
+				TileMoved{Tile: Tile{From: Cell{0, 1}, To: Cell{0, 2}, Value: 4}},
+				TileMoved{Tile: Tile{From: Cell{0, 0}, To: Cell{0, 1}, Value: 2}},
+				TileSpawned{Spawn: Spawn{Cell: Cell{0, 0}, Value: 4}},
+				PhaseChanged{From: NotStarted, To: Finished},
+				GameLost{Score: 0, Highest: 128},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := eventGame(t, tt.blocks, tt.spawn)
+			events := record(g)
+			tt.play(g)
+			checkEvents(t, *events, tt.want)
+		})
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	g := eventGame(t, mergeBoard, mergeSpawn)
+	var first, second int
+	unsubscribe := g.Subscribe(ObserverFunc(func(Event) { first++ }))
+	g.Subscribe(ObserverFunc(func(Event) { second++ }))
+
+	g.Push(Left)
+	if first == 0 || first != second {
+		t.Fatalf("observers got %d and %d events, want the same number", first, second)
+	}
+	unsubscribe()
+	unsubscribe() // a second call does nothing
+	n := first
+	pushAny(t, g)
+	if first != n {
+		t.Errorf("unsubscribed observer got %d more events", first-n)
+	}
+	if second == n {
+		t.Error("observer got no events after another one unsubscribed")
+	}
+}
+
+func TestEventsSurviveRestart(t *testing.T) {
+	g := eventGame(t, mergeBoard, mergeSpawn)
+	g.Push(Left)
+	events := record(g)
+
+	g.Restart(2)
+	checkEvents(t, *events, []Event{PhaseChanged{From: NotFinished, To: NotStarted}})
+
+	*events = nil
+	pushAny(t, g)
+	got := *events
+	if len(got) == 0 || got[len(got)-1] != (PhaseChanged{From: NotStarted, To: NotFinished}) {
+		t.Errorf("events of the first push after a restart: %v", got)
+	}
+}
+
+func TestReentrantDispatch(t *testing.T) {
+	g := eventGame(t, mergeBoard, mergeSpawn)
+	var got []Event
+	g.Subscribe(ObserverFunc(func(e Event) {
+		got = append(got, e)
+		// undone from inside the observer, its events follow the ones of the push
+		if _, ok := e.(TileSpawned); ok {
+			g.Undo()
+		}
+	}))
+	later := record(g)
+
+	g.Push(Left)
+	want := mergeAnd(
+		PhaseChanged{From: NotStarted, To: NotFinished},
+		UndoApplied{Move: Move{Dir: Left, Spawn: mergeSpawn}, UndosLeft: 1},
+		ScoreChanged{Score: 0, Delta: -4},
+	)
+	checkEvents(t, got, want)
+	checkEvents(t, *later, want)
+}
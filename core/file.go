@@ -1,8 +1,282 @@
 package core
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
 const (
+	appdir   = "2048"
 	datafile = "lastgame.data.json"
 )
 
-// TODO: add support for windows
-// TODO: add support for linux
+// ErrNoSavedGame is returned by LoadGame when there is no game to resume.
+var ErrNoSavedGame = errors.New("no saved game to resume")
+
+// directory set by SetDataDir
+var dataDir string
+
+// SetDataDir changes the directory in which game data is kept,
+// an empty dir restores the default.
+func SetDataDir(dir string) {
+	dataDir = dir
+}
+
+// DataDir returns the directory in which game data is kept, unless
+// changed by SetDataDir:
+//
+//	linux (and other unix) - $XDG_DATA_HOME/2048 or ~/.local/share/2048
+//	darwin                 - ~/Library/Application Support/2048
+//	windows                - %LOCALAPPDATA%\2048
+func DataDir() (string, error) {
+	if dataDir != "" {
+		return dataDir, nil
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, appdir), nil
+		}
+		return "", errors.New("%LOCALAPPDATA% is not defined")
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, "Library", "Application Support", appdir), nil
+	default:
+		if dir := os.Getenv("XDG_DATA_HOME"); dir != "" && filepath.IsAbs(dir) {
+			return filepath.Join(dir, appdir), nil
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "share", appdir), nil
+	}
+}
+
+func dataFilePath(name string) (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// writeDataFile atomically replaces the named file in the data directory
+func writeDataFile(name string, data []byte) error {
+	path, err := dataFilePath(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+type savedState struct {
+	Board    [][]int `json:"board"`
+	Score    int     `json:"score"`
+	BlockCnt int     `json:"blockCnt"`
+	Merges   int     `json:"merges"`
+}
+
+type savedRedo struct {
+	State savedState `json:"state"`
+	Move  Move       `json:"move"`
+}
+
+type savedGame struct {
+	ID        string        `json:"id,omitempty"` // saves before games had ids have none
+	Player    string        `json:"player"`
+	Target    int           `json:"target"`
+	Rows      int           `json:"rows"`
+	Cols      int           `json:"cols"`
+	Size      int           `json:"size,omitempty"` // square boards, before rows and cols
+	Phase     Phase         `json:"phase"`
+	State     savedState    `json:"state"`
+	UndoStack []savedState  `json:"undoStack"`
+	RedoStack []savedRedo   `json:"redoStack"`
+	UndoMode  UndoMode      `json:"undoMode"`
+	Undos     int           `json:"undos"`
+	UndosLeft int           `json:"undosLeft"`
+	Elapsed   time.Duration `json:"elapsedNs"`
+	Endless   bool          `json:"endless"`
+	Seed      int64         `json:"seed"`
+	Draws     uint64        `json:"draws"`
+	Stats     *Stats        `json:"stats,omitempty"`
+	Start     []Spawn       `json:"start"`
+	History   []Move        `json:"history"`
+}
+
+func (s *stableState) save() savedState {
+	return savedState{
+		Board:    s.board.Blocks(),
+		Score:    s.score,
+		BlockCnt: s.blockCnt,
+		Merges:   s.merges,
+	}
+}
+
+func (s *stableState) restore(saved savedState) error {
+	board, err := BoardOf(saved.Board)
+	if err != nil {
+		return err
+	}
+	if board.Rows() != s.board.Rows() || board.Cols() != s.board.Cols() {
+		return errors.New("board size mismatch")
+	}
+	if board.BlockCount() != saved.BlockCnt {
+		return errors.New("block count mismatch")
+	}
+
+	s.board, s.score, s.blockCnt, s.merges = board, saved.Score, saved.BlockCnt, saved.Merges
+	return nil
+}
+
+// Save writes the full state of the game to the data file,
+// from which it can later be restored with LoadGame.
+func (g *Game) Save() error {
+	saved := &savedGame{
+		ID:        g.id,
+		Player:    g.Player,
+		Target:    g.Target,
+		Rows:      g.Rows,
+		Cols:      g.Cols,
+		Phase:     g.Phase,
+		State:     g.stableState.save(),
+		UndoMode:  g.undoMode,
+		Undos:     g.undos,
+		UndosLeft: g.undosLeft,
+		Elapsed:   g.Duration(),
+		Endless:   g.endless,
+		Seed:      g.src.seed,
+		Draws:     g.src.draws,
+		Stats:     &g.stats,
+		Start:     g.start,
+		History:   g.history,
+	}
+	for _, s := range g.undoStack {
+		saved.UndoStack = append(saved.UndoStack, s.save())
+	}
+	for _, entry := range g.redoStack {
+		saved.RedoStack = append(saved.RedoStack, savedRedo{
+			State: entry.state.save(),
+			Move:  entry.move,
+		})
+	}
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	return writeDataFile(datafile, data)
+}
+
+// RemoveSave deletes the data file, if there is one.
+func RemoveSave() error {
+	path, err := dataFilePath(datafile)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// LoadGame restores the game last written by Save.
+func LoadGame() (*Game, error) {
+	path, err := dataFilePath(datafile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoSavedGame
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var saved savedGame
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("corrupted save file %s: %v", path, err)
+	}
+
+	if saved.Rows == 0 && saved.Cols == 0 {
+		saved.Rows, saved.Cols = saved.Size, saved.Size
+	}
+
+	src := newRNGSource(saved.Seed, saved.Draws)
+	game, err := newEmptyGame(saved.Player, saved.Rows, saved.Cols, saved.Target, saved.UndosLeft, src)
+	if err != nil {
+		return nil, fmt.Errorf("corrupted save file %s: %v", path, err)
+	}
+
+	if saved.UndoMode < ClassicUndo || saved.UndoMode > UnlimitedUndo {
+		return nil, fmt.Errorf("corrupted save file %s: invalid undo mode %d", path, saved.UndoMode)
+	}
+	if err := game.stableState.restore(saved.State); err != nil {
+		return nil, fmt.Errorf("corrupted save file %s: state: %v", path, err)
+	}
+	if len(saved.UndoStack) > len(saved.History) {
+		return nil, fmt.Errorf("corrupted save file %s: undo history longer than move history", path)
+	}
+	for i, s := range saved.UndoStack {
+		state := game.allocState()
+		if err := state.restore(s); err != nil {
+			return nil, fmt.Errorf("corrupted save file %s: undo state %d: %v", path, i, err)
+		}
+		game.undoStack = append(game.undoStack, state)
+	}
+	for i, r := range saved.RedoStack {
+		state := game.allocState()
+		if err := state.restore(r.State); err != nil {
+			return nil, fmt.Errorf("corrupted save file %s: redo state %d: %v", path, i, err)
+		}
+		game.redoStack = append(game.redoStack, redoEntry{state: state, move: r.Move})
+	}
+
+	if saved.ID != "" {
+		game.id = saved.ID
+	}
+	game.Phase = saved.Phase
+	game.undos = max(saved.Undos, saved.UndosLeft) // saves before undos were stored
+	game.elapsed = saved.Elapsed
+	if saved.Stats != nil { // saves before stats were kept have none
+		game.stats = saved.Stats.clone()
+	}
+	game.endless = saved.Endless
+	game.start = saved.Start
+	game.history = saved.History
+	game.SetUndoMode(saved.UndoMode)
+
+	return game, nil
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// makes a temporary directory, removed by the returned function
+func tempDir(t *testing.T) (dir string, remove func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "2048")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// plays a seeded game, leaving a move to undo and another to redo
+func savedTestGame(t *testing.T) *Game {
+	g, _ := NewRectGame("p", 4, 5, 2048, 3, 7)
+	g.SetUndoMode(UnlimitedUndo)
+	playMoves(t, g, 20)
+	g.Undo()
+	return g
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	SetDataDir(dir)
+	defer SetDataDir("")
+
+	g := savedTestGame(t)
+	if err := g.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkState(t, loaded, g.Board(), g.Score(), g.Moves())
+	if loaded.Rows != g.Rows || loaded.Cols != g.Cols || loaded.Target != g.Target {
+		t.Errorf("loaded a %dx%d game to %d, want %dx%d to %d",
+			loaded.Rows, loaded.Cols, loaded.Target, g.Rows, g.Cols, g.Target)
+	}
+	if loaded.UndosLeft() != g.UndosLeft() || loaded.Undos() != g.Undos() || loaded.UndoMode() != g.UndoMode() {
+		t.Errorf("loaded undos %d of %d (%v), want %d of %d (%v)",
+			loaded.UndosLeft(), loaded.Undos(), loaded.UndoMode(), g.UndosLeft(), g.Undos(), g.UndoMode())
+	}
+	if loaded.CanUndo() != g.CanUndo() || loaded.CanRedo() != g.CanRedo() {
+		t.Errorf("loaded CanUndo %v CanRedo %v, want %v %v",
+			loaded.CanUndo(), loaded.CanRedo(), g.CanUndo(), g.CanRedo())
+	}
+	if loaded.Seed() != g.Seed() || loaded.id != g.id {
+		t.Errorf("loaded seed %d id %q, want %d %q", loaded.Seed(), loaded.id, g.Seed(), g.id)
+	}
+	if got, want := loaded.Stats(), g.Stats(); got.TotalMoves() != want.TotalMoves() {
+		t.Errorf("loaded %d moves in the stats, want %d", got.TotalMoves(), want.TotalMoves())
+	}
+
+	// both games go on the same way, with the same spawns
+	loaded.Redo()
+	g.Redo()
+	checkState(t, loaded, g.Board(), g.Score(), g.Moves())
+	for i := 0; i < 10; i++ {
+		pushAny(t, g)
+		pushAny(t, loaded)
+		checkState(t, loaded, g.Board(), g.Score(), g.Moves())
+	}
+	loaded.Undo()
+	g.Undo()
+	checkState(t, loaded, g.Board(), g.Score(), g.Moves())
+}
+
+func TestLoadLegacySave(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	SetDataDir(dir)
+	defer SetDataDir("")
+
+	g, _ := NewGameWithSeed("p", 5, 2048, 3, 7)
+	playMoves(t, g, 5)
+	g.Save()
+	corruptSave(t, func(saved map[string]interface{}) {
+		saved["size"] = saved["rows"]
+		delete(saved, "rows")
+		delete(saved, "cols")
+		saved["phase"] = float64(NotFinished) // phases were saved as numbers
+	})
+
+	loaded, err := LoadGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Phase != NotFinished {
+		t.Errorf("loaded phase %v, want %v", loaded.Phase, NotFinished)
+	}
+	if loaded.Rows != 5 || loaded.Cols != 5 {
+		t.Errorf("loaded a %dx%d board, want 5x5", loaded.Rows, loaded.Cols)
+	}
+	checkState(t, loaded, g.Board(), g.Score(), g.Moves())
+}
+
+// rewrites the save file after changing it
+func corruptSave(t *testing.T, change func(saved map[string]interface{})) {
+	t.Helper()
+	path, _ := dataFilePath(datafile)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved map[string]interface{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	change(saved)
+	if data, err = json.Marshal(saved); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRejectsCorruptedSave(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	SetDataDir(dir)
+	defer SetDataDir("")
+
+	state := func(saved map[string]interface{}) map[string]interface{} {
+		return saved["state"].(map[string]interface{})
+	}
+	tests := []struct {
+		name   string
+		change func(saved map[string]interface{})
+		err    string
+	}{
+		{"board size", func(saved map[string]interface{}) {
+			saved["cols"] = 4.0
+		}, "board size mismatch"},
+		{"block count", func(saved map[string]interface{}) {
+			state(saved)["blockCnt"] = state(saved)["blockCnt"].(float64) + 1
+		}, "block count mismatch"},
+		{"undo stack", func(saved map[string]interface{}) {
+			saved["history"] = []interface{}{}
+		}, "undo history longer than move history"},
+		{"undo state", func(saved map[string]interface{}) {
+			undo := saved["undoStack"].([]interface{})
+			undo[0].(map[string]interface{})["board"] = [][]int{{2, 4}}
+		}, "undo state 0"},
+		{"phase", func(saved map[string]interface{}) {
+			saved["phase"] = "paused"
+		}, "invalid phase"},
+		{"undo mode", func(saved map[string]interface{}) {
+			saved["undoMode"] = 2.0
+		}, "invalid undo mode 2"},
+		{"invalid json", func(saved map[string]interface{}) {
+			saved["rows"] = "four"
+		}, "corrupted save file"},
+	}
+	for _, test := range tests {
+		savedTestGame(t).Save()
+		corruptSave(t, test.change)
+		_, err := LoadGame()
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: LoadGame error %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestLoadNoSave(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	SetDataDir(dir)
+	defer SetDataDir("")
+
+	if _, err := LoadGame(); err != ErrNoSavedGame {
+		t.Errorf("LoadGame error %v, want %v", err, ErrNoSavedGame)
+	}
+}
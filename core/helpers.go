@@ -1,6 +1,12 @@
 package core
 
-import "math/rand"
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"math/rand"
+	"strconv"
+	"time"
+)
 
 func min(a int, b int) int {
 	if a < b {
@@ -23,3 +29,39 @@ func ptrue(p float64, rng *rand.Rand) bool {
 	}
 	return false
 }
+
+// rngSource wraps a seeded source and counts the values drawn from it,
+// so that the exact position of the generator can be saved and restored
+type rngSource struct {
+	src   rand.Source
+	seed  int64
+	draws uint64
+}
+
+func newRNGSource(seed int64, draws uint64) *rngSource {
+	s := &rngSource{src: rand.NewSource(seed), seed: seed}
+	for s.draws < draws {
+		s.Int63()
+	}
+	return s
+}
+
+func (s *rngSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *rngSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed, s.draws = seed, 0
+}
+
+// returns a random id, unique to the game, falling back
+// on the current time if there is no source of randomness
+func newGameID() string {
+	buf := make([]byte, 8)
+	if _, err := crand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(buf)
+}
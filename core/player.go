@@ -0,0 +1,139 @@
+package core
+
+import "time"
+
+// View is a read-only view of a game, given to players
+// to choose their next action.
+type View interface {
+	Board() Board
+	Score() int
+	Seed() int64
+	Moves() int
+	History() []Move
+	UndosLeft() int
+	CanUndo() bool
+	CanRedo() bool
+	HighestBlock() int
+	Merges() int
+	Endless() bool
+	Outcome() Outcome
+}
+
+type ActionKind int
+
+const (
+	PushAction ActionKind = iota
+	UndoAction
+	RedoAction
+	KeepGoingAction
+	QuitAction
+	NewGameAction // restart the game with a new seed
+)
+
+func (k ActionKind) String() string {
+	switch k {
+	case PushAction:
+		return "push"
+	case UndoAction:
+		return "undo"
+	case RedoAction:
+		return "redo"
+	case KeepGoingAction:
+		return "keep going"
+	case QuitAction:
+		return "quit"
+	case NewGameAction:
+		return "new game"
+	default:
+		return "invalid action"
+	}
+}
+
+// Action is what a player does in its turn.
+type Action struct {
+	Kind ActionKind
+	Dir  Direction // direction of a PushAction
+}
+
+// PushTo returns the action of pushing the blocks in the given direction.
+func PushTo(dir Direction) Action {
+	return Action{Kind: PushAction, Dir: dir}
+}
+
+// Player is anything that drives a game: a human at the keyboard,
+// a bot, a replay or a remote player.
+type Player interface {
+	// Act returns the next action of the player. While the game is won
+	// (and not yet continued) only KeepGoingAction, QuitAction and
+	// NewGameAction have an effect.
+	Act(v View) Action
+}
+
+// PlayerFunc is an ordinary function used as a Player.
+type PlayerFunc func(v View) Action
+
+func (f PlayerFunc) Act(v View) Action {
+	return f(v)
+}
+
+// MoveFunc chooses the next move of the game, ok is false
+// if no move is possible.
+type MoveFunc func(v View) (dir Direction, ok bool)
+
+// Bot returns a player which plays the moves chosen by next, keeps going
+// after the game is won, and quits when next finds no move.
+func Bot(next MoveFunc) Player {
+	return PlayerFunc(func(v View) Action {
+		if v.Outcome() == GameOverWin {
+			return Action{Kind: KeepGoingAction}
+		}
+		dir, ok := next(v)
+		if !ok {
+			return Action{Kind: QuitAction}
+		}
+		return PushTo(dir)
+	})
+}
+
+// Apply performs the action and reports whether it changed the game,
+// a push that moves no blocks and a rejected undo change nothing.
+func (g *Game) Apply(a Action) bool {
+	switch a.Kind {
+	case PushAction:
+		moves := len(g.history)
+		g.Push(a.Dir)
+		return len(g.history) > moves
+	case UndoAction:
+		return g.Undo()
+	case RedoAction:
+		return g.Redo()
+	case KeepGoingAction:
+		return g.KeepGoing()
+	case QuitAction:
+		g.Quit()
+		return true
+	case NewGameAction:
+		g.Restart(time.Now().UnixNano())
+		return true
+	default:
+		return false
+	}
+}
+
+// Play lets the player drive the game until no moves remain or the player
+// quits, and returns the outcome: GameOver or GameQuit. After every action
+// other than quitting, update (if not nil) is called with the action and
+// whether it changed the game.
+func Play(g *Game, p Player, update func(a Action, ok bool)) Outcome {
+	for {
+		if outcome := g.Outcome(); outcome == GameOver || outcome == GameQuit {
+			return outcome
+		}
+
+		a := p.Act(g)
+		ok := g.Apply(a)
+		if a.Kind != QuitAction && update != nil {
+			update(a, ok)
+		}
+	}
+}
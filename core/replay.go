@@ -0,0 +1,184 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// Spawn is a block that appeared on the board.
+type Spawn struct {
+	Cell
+	Value int `json:"value"`
+}
+
+// Move is a push that moved at least one block, together
+// with the block that was spawned after it.
+type Move struct {
+	Dir   Direction `json:"dir"`
+	Spawn Spawn     `json:"spawn"`
+}
+
+// Replay is a complete record of how a game unfolded.
+type Replay struct {
+	Player string  `json:"player"`
+	Rows   int     `json:"rows"`
+	Cols   int     `json:"cols"`
+	Size   int     `json:"size,omitempty"` // square boards, before rows and cols
+	Target int     `json:"target"`
+	Seed   int64   `json:"seed"`
+	Start  []Spawn `json:"start"`
+	Moves  []Move  `json:"moves"`
+}
+
+// Replay returns the record of the game up to its current state.
+func (g *Game) Replay() *Replay {
+	return &Replay{
+		Player: g.Player,
+		Rows:   g.Rows,
+		Cols:   g.Cols,
+		Target: g.Target,
+		Seed:   g.Seed(),
+		Start:  append([]Spawn(nil), g.start...),
+		Moves:  g.History(),
+	}
+}
+
+func (r *Replay) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func ReadReplay(path string) (*Replay, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Replay
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("invalid replay file %s: %v", path, err)
+	}
+	if r.Rows == 0 && r.Cols == 0 {
+		r.Rows, r.Cols, r.Size = r.Size, r.Size, 0
+	}
+	return &r, nil
+}
+
+// Replayer plays back a replay one move at a time. It is a Player
+// of the game returned by Game.
+type Replayer struct {
+	game   *Game
+	replay *Replay
+	next   int
+	err    error
+}
+
+func NewReplayer(r *Replay) (*Replayer, error) {
+	game, err := newEmptyGame(r.Player, r.Rows, r.Cols, r.Target, 0, newRNGSource(r.Seed, 0))
+	if err != nil {
+		return nil, fmt.Errorf("invalid replay: %v", err)
+	}
+
+	if len(r.Start) == 0 {
+		return nil, errors.New("invalid replay: no starting blocks")
+	}
+	for _, s := range r.Start {
+		if err := game.place(s); err != nil {
+			return nil, fmt.Errorf("invalid replay: %v", err)
+		}
+	}
+	game.start = append(game.start, r.Start...)
+
+	return &Replayer{
+		game:   game,
+		replay: r,
+	}, nil
+}
+
+// Game returns the game in which the replay is played back,
+// it must not be modified by the caller.
+func (p *Replayer) Game() *Game {
+	return p.game
+}
+
+// Pos returns the number of moves played back so far.
+func (p *Replayer) Pos() int {
+	return p.next
+}
+
+func (p *Replayer) Len() int {
+	return len(p.replay.Moves)
+}
+
+func (p *Replayer) Done() bool {
+	return p.next == len(p.replay.Moves)
+}
+
+// Err returns the reason the replay could not be played back further,
+// nil if it is valid so far.
+func (p *Replayer) Err() error {
+	return p.err
+}
+
+// Act implements Player, it plays back the next move of the replay (with
+// the block spawned after it), and quits at the end of the replay or when
+// the replay turns out to be invalid.
+func (p *Replayer) Act(v View) Action {
+	if p.Done() || p.err != nil {
+		return Action{Kind: QuitAction}
+	}
+	if v.Outcome() == GameOverWin {
+		return Action{Kind: KeepGoingAction} // the player kept going after winning
+	}
+
+	m := p.replay.Moves[p.next]
+	if err := p.check(m); err != nil {
+		p.err = fmt.Errorf("invalid replay: move %d: %v", p.next+1, err)
+		return Action{Kind: QuitAction}
+	}
+
+	p.game.forced = &m.Spawn
+	p.next++
+	return PushTo(m.Dir)
+}
+
+// checks that the move can be played in the current state of the game
+func (p *Replayer) check(m Move) error {
+	if p.game.Outcome() == GameOver {
+		return errors.New("after the end of the game")
+	}
+	next, _, moved := p.game.board.Move(m.Dir)
+	if !moved {
+		return fmt.Errorf("%v moves no blocks", m.Dir)
+	}
+	if next.Block(m.Spawn.Row, m.Spawn.Col) != 0 {
+		return fmt.Errorf("cannot place block at (%d,%d)", m.Spawn.Row, m.Spawn.Col)
+	}
+	if m.Spawn.Value != 2 && m.Spawn.Value != 4 {
+		return fmt.Errorf("invalid spawned block %d", m.Spawn.Value)
+	}
+	return nil
+}
+
+// Step plays back the next move of the replay.
+func (p *Replayer) Step() (Outcome, error) {
+	if p.Done() {
+		return p.game.Outcome(), errors.New("end of replay")
+	}
+
+	for {
+		a := p.Act(p.game)
+		if a.Kind == QuitAction {
+			return p.game.Outcome(), p.err
+		}
+		p.game.Apply(a)
+		if a.Kind == PushAction {
+			return p.game.Outcome(), nil
+		}
+	}
+}
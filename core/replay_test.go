@@ -0,0 +1,83 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// plays a seeded game with an undo on the way, and returns it
+func recordedGame(t *testing.T) *Game {
+	g, _ := NewGameWithSeed("p", 4, 2048, 3, 5)
+	playMoves(t, g, 30)
+	g.Undo()
+	playMoves(t, g, 30)
+	return g
+}
+
+func TestReplayReproducesGame(t *testing.T) {
+	g := recordedGame(t)
+	dir, remove := tempDir(t)
+	defer remove()
+	path := filepath.Join(dir, "replay.json")
+	if err := g.Replay().WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	r, err := ReadReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewReplayer(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for !p.Done() {
+		if _, err := p.Step(); err != nil {
+			t.Fatalf("step %d: %v", p.Pos(), err)
+		}
+	}
+	checkState(t, p.Game(), g.Board(), g.Score(), g.Moves())
+
+	// played back as a player
+	p, _ = NewReplayer(r)
+	Play(p.Game(), p, nil)
+	if p.Err() != nil {
+		t.Fatal(p.Err())
+	}
+	checkState(t, p.Game(), g.Board(), g.Score(), g.Moves())
+}
+
+func TestReplayRejectsInvalidSpawn(t *testing.T) {
+	r := recordedGame(t).Replay()
+	r.Moves[10].Spawn.Value = 8
+	p, _ := NewReplayer(r)
+	Play(p.Game(), p, nil)
+	if p.Err() == nil {
+		t.Fatal("spawned 8 was played back")
+	}
+	if p.Pos() != 10 {
+		t.Errorf("played back %d moves, want 10", p.Pos())
+	}
+
+	// a block spawned on a cell taken after the push
+	r = recordedGame(t).Replay()
+	p, _ = NewReplayer(r)
+	for p.Pos() < 10 {
+		p.Step()
+	}
+	next, _, _ := p.Game().Board().Move(r.Moves[10].Dir)
+	for i := 0; i < next.Rows()*next.Cols(); i++ {
+		if c := next.cell(i); next.Block(c.Row, c.Col) != 0 {
+			r.Moves[10].Spawn.Cell = c
+			break
+		}
+	}
+	p, _ = NewReplayer(r)
+	Play(p.Game(), p, nil)
+	if p.Err() == nil {
+		t.Fatal("spawn on a taken cell was played back")
+	}
+	if p.Pos() != 10 {
+		t.Errorf("played back %d moves, want 10", p.Pos())
+	}
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	scoresfile = "highscores.json"
+
+	// number of scores kept for every board
+	MaxHighScores = 10
+)
+
+// BoardKey identifies the games whose scores are compared with each other.
+type BoardKey struct {
+	Rows   int `json:"rows"`
+	Cols   int `json:"cols"`
+	Target int `json:"target"`
+	Undos  int `json:"undos"` // undos the games started with
+}
+
+func (k BoardKey) String() string {
+	return fmt.Sprintf("%dx%d board, target %d, %d undos", k.Rows, k.Cols, k.Target, k.Undos)
+}
+
+// HighScore is an entry of a leaderboard.
+type HighScore struct {
+	Player   string        `json:"player"`
+	Score    int           `json:"score"`
+	MaxTile  int           `json:"maxTile"`
+	Moves    int           `json:"moves"`
+	Duration time.Duration `json:"durationNs"`
+	Date     time.Time     `json:"date"`
+	Seed     int64         `json:"seed"`
+	GameID   string        `json:"gameId,omitempty"` // identifies the game
+}
+
+// Leaderboard holds the best scores of a board, highest first.
+type Leaderboard struct {
+	Board  BoardKey    `json:"board"`
+	Scores []HighScore `json:"scores"`
+}
+
+// HighScores holds the leaderboards of all the boards played so far.
+type HighScores struct {
+	Leaderboards []Leaderboard `json:"leaderboards"`
+}
+
+// LoadHighScores reads the high scores from the data file,
+// there are none if the file does not exist yet.
+func LoadHighScores() (*HighScores, error) {
+	path, err := dataFilePath(scoresfile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &HighScores{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var h HighScores
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("corrupted high score file %s: %v", path, err)
+	}
+	return &h, nil
+}
+
+func (h *HighScores) Save() error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeDataFile(scoresfile, data)
+}
+
+// Leaderboard returns the leaderboard of the board, nil if there is none.
+func (h *HighScores) Leaderboard(key BoardKey) *Leaderboard {
+	for i := range h.Leaderboards {
+		if h.Leaderboards[i].Board == key {
+			return &h.Leaderboards[i]
+		}
+	}
+	return nil
+}
+
+// Add enters the score in the leaderboard of the board, and returns its
+// rank (starting from 1), or 0 if it is not good enough to be kept. An
+// earlier score of the same game (by GameID) is replaced by a higher one,
+// so a game that was won and then continued is only entered once.
+func (h *HighScores) Add(key BoardKey, score HighScore) int {
+	lb := h.Leaderboard(key)
+	if lb == nil {
+		h.Leaderboards = append(h.Leaderboards, Leaderboard{Board: key})
+		lb = &h.Leaderboards[len(h.Leaderboards)-1]
+	}
+
+	for i, other := range lb.Scores {
+		if score.GameID == "" || other.GameID != score.GameID {
+			continue
+		}
+		if other.Score >= score.Score {
+			return i + 1
+		}
+		lb.Scores = append(lb.Scores[:i], lb.Scores[i+1:]...)
+		break
+	}
+
+	// equal scores are ranked by date, the earlier one first
+	rank := sort.Search(len(lb.Scores), func(i int) bool {
+		return lb.Scores[i].Score < score.Score
+	})
+	if rank >= MaxHighScores {
+		return 0
+	}
+
+	lb.Scores = append(lb.Scores, HighScore{})
+	copy(lb.Scores[rank+1:], lb.Scores[rank:])
+	lb.Scores[rank] = score
+	if len(lb.Scores) > MaxHighScores {
+		lb.Scores = lb.Scores[:MaxHighScores]
+	}
+	return rank + 1
+}
+
+// BoardKey returns the key of the leaderboard of the game.
+func (g *Game) BoardKey() BoardKey {
+	return BoardKey{
+		Rows:   g.Rows,
+		Cols:   g.Cols,
+		Target: g.Target,
+		Undos:  g.undos,
+	}
+}
+
+// RecordHighScore enters the score of a finished game in the high scores,
+// and returns its rank (starting from 1), or 0 if it is not high enough.
+// Recording the same game again updates its entry.
+func (g *Game) RecordHighScore() (rank int, err error) {
+	if g.Phase != Finished {
+		return 0, fmt.Errorf("game is not finished")
+	}
+
+	h, err := LoadHighScores()
+	if err != nil {
+		return 0, err
+	}
+
+	rank = h.Add(g.BoardKey(), HighScore{
+		Player:   g.Player,
+		Score:    g.score,
+		MaxTile:  g.HighestBlock(),
+		Moves:    g.Moves(),
+		Duration: g.Duration(),
+		Date:     time.Now(),
+		Seed:     g.Seed(),
+		GameID:   g.id,
+	})
+	if rank == 0 {
+		return 0, nil
+	}
+	return rank, h.Save()
+}
@@ -0,0 +1,75 @@
+package core
+
+import "testing"
+
+func TestHighScoresAddSameSeed(t *testing.T) {
+	key := BoardKey{Rows: 4, Cols: 4, Target: 2048, Undos: 3}
+	var h HighScores
+
+	if rank := h.Add(key, HighScore{Player: "p", Score: 2416, Seed: 42, GameID: "a"}); rank != 1 {
+		t.Fatalf("first game ranked %d, want 1", rank)
+	}
+	// another game played with the same seed is a different entry
+	if rank := h.Add(key, HighScore{Player: "p", Score: 16, Seed: 42, GameID: "b"}); rank != 2 {
+		t.Fatalf("second game ranked %d, want 2", rank)
+	}
+	if n := len(h.Leaderboard(key).Scores); n != 2 {
+		t.Fatalf("%d scores kept, want 2", n)
+	}
+}
+
+func TestHighScoresAddSameGame(t *testing.T) {
+	key := BoardKey{Rows: 4, Cols: 4, Target: 2048, Undos: 3}
+	var h HighScores
+	h.Add(key, HighScore{Player: "p", Score: 500, GameID: "other"})
+	h.Add(key, HighScore{Player: "p", Score: 300, GameID: "a"})
+
+	// a lower score of the same game never replaces its entry
+	if rank := h.Add(key, HighScore{Player: "p", Score: 100, GameID: "a"}); rank != 2 {
+		t.Errorf("lower score of the same game ranked %d, want 2", rank)
+	}
+	// a higher one does
+	if rank := h.Add(key, HighScore{Player: "p", Score: 1000, GameID: "a"}); rank != 1 {
+		t.Errorf("higher score of the same game ranked %d, want 1", rank)
+	}
+
+	scores := h.Leaderboard(key).Scores
+	if len(scores) != 2 || scores[0].Score != 1000 || scores[1].Score != 500 {
+		t.Errorf("scores = %+v, want 1000 and 500", scores)
+	}
+}
+
+func TestRecordHighScoreKeepsGamesWithSameSeed(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	SetDataDir(dir)
+	defer SetDataDir("")
+
+	// two games with the same seed, played differently
+	finish := func(g *Game, dirs ...Direction) {
+		for i := 0; g.Phase != Finished; i++ {
+			g.Push(dirs[i%len(dirs)])
+		}
+	}
+	first, _ := NewGameWithSeed("p", 4, 2048, 0, 42)
+	finish(first, Down, Right, Up, Left)
+	second, _ := NewGameWithSeed("p", 4, 2048, 0, 42)
+	finish(second, Up, Left, Down, Right)
+	if first.Score() <= second.Score() {
+		t.Fatalf("scores %d and %d, want the first game higher", first.Score(), second.Score())
+	}
+
+	for _, g := range []*Game{first, second} {
+		if _, err := g.RecordHighScore(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h, err := LoadHighScores()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scores := h.Leaderboard(first.BoardKey()).Scores; len(scores) != 2 || scores[0].Score != first.Score() {
+		t.Errorf("scores = %+v, want both games, %d first", scores, first.Score())
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import "time"
+
+// Stats are statistics about how a game was played. Unlike the score,
+// they are not restored by an undo: undone moves still count.
+type Stats struct {
+	Moves        [4]int                `json:"moves"`        // pushes that moved a block, indexed by Direction
+	Merges       map[int]int           `json:"merges"`       // merges by the value of the resulting block
+	WastedPushes int                   `json:"wastedPushes"` // pushes that moved no block
+	Undos        int                   `json:"undos"`
+	MoveTime     time.Duration         `json:"moveTime"` // time spent choosing the moves
+	FastestMove  time.Duration         `json:"fastestMove"`
+	SlowestMove  time.Duration         `json:"slowestMove"`
+	Reached      map[int]time.Duration `json:"reached"` // play time at which a block was first merged
+}
+
+func newStats() Stats {
+	return Stats{
+		Merges:  make(map[int]int),
+		Reached: make(map[int]time.Duration),
+	}
+}
+
+// TotalMoves returns the number of pushes that moved a block.
+func (s *Stats) TotalMoves() int {
+	total := 0
+	for _, n := range s.Moves {
+		total += n
+	}
+	return total
+}
+
+// AverageMoveTime returns the average time spent choosing a move.
+func (s *Stats) AverageMoveTime() time.Duration {
+	if total := s.TotalMoves(); total > 0 {
+		return s.MoveTime / time.Duration(total)
+	}
+	return 0
+}
+
+func (s *Stats) clone() Stats {
+	c := *s
+	c.Merges = make(map[int]int, len(s.Merges))
+	for block, n := range s.Merges {
+		c.Merges[block] = n
+	}
+	c.Reached = make(map[int]time.Duration, len(s.Reached))
+	for block, t := range s.Reached {
+		c.Reached[block] = t
+	}
+	return c
+}
+
+func (s *Stats) addMove(dir Direction, took time.Duration) {
+	s.Moves[dir]++
+	s.MoveTime += took
+	if s.TotalMoves() == 1 || took < s.FastestMove {
+		s.FastestMove = took
+	}
+	if took > s.SlowestMove {
+		s.SlowestMove = took
+	}
+}
+
+func (s *Stats) addMerge(block int, at time.Duration) {
+	s.Merges[block]++
+	if _, ok := s.Reached[block]; !ok {
+		s.Reached[block] = at
+	}
+}
+
+// Stats returns the statistics of the game so far.
+func (g *Game) Stats() Stats {
+	return g.stats.clone()
+}
@@ -0,0 +1,31 @@
+package core
+
+import "time"
+
+// Summary describes how a game went.
+type Summary struct {
+	Player    string
+	Outcome   Outcome
+	Won       bool // whether the target was reached, even if the game went on
+	Score     int
+	Highest   int // highest block
+	Moves     int
+	Merges    int
+	UndosUsed int
+	Duration  time.Duration
+}
+
+// Summary returns the summary of the game in its current state.
+func (g *Game) Summary() Summary {
+	return Summary{
+		Player:    g.Player,
+		Outcome:   g.Outcome(),
+		Won:       g.endless || g.board.Highest() >= g.Target,
+		Score:     g.score,
+		Highest:   g.HighestBlock(),
+		Moves:     g.Moves(),
+		Merges:    g.merges,
+		UndosUsed: g.UndosUsed(),
+		Duration:  g.Duration(),
+	}
+}
@@ -0,0 +1,42 @@
+package core
+
+// Tile is the movement of a block in a push.
+type Tile struct {
+	From  Cell `json:"from"`
+	To    Cell `json:"to"`
+	Value int  `json:"value"` // value before the push
+
+	// the block merged into the one that moved to the same cell before it,
+	// which doubles the value of that cell
+	Merged bool `json:"merged"`
+}
+
+// Moved reports whether the block left its cell.
+func (t Tile) Moved() bool {
+	return t.From != t.To
+}
+
+// Transition describes how the board changed in a push: where every block
+// went, and which block was spawned afterwards.
+type Transition struct {
+	Dir   Direction `json:"dir"`
+	Tiles []Tile    `json:"tiles"` // every block of the board before the push
+	Spawn Spawn     `json:"spawn"` // zero Value if no block was spawned
+}
+
+// Tiles returns the movements of the blocks pushed in the direction.
+func (b Board) Tiles(dir Direction) []Tile {
+	var tiles []Tile
+	b.move(dir, func(t Tile) {
+		tiles = append(tiles, t)
+	})
+	return tiles
+}
+
+// Transition returns how the board changed in the last push, or redo.
+// It has no tiles if the board changed in any other way since then (an
+// undo, or the start of the game), renderers should then redraw the board
+// from scratch.
+func (g *Game) Transition() Transition {
+	return g.transition
+}
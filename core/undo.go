@@ -0,0 +1,170 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+type UndoMode int
+
+const (
+	// only the last move can be undone, and two consecutive undos are not allowed
+	ClassicUndo UndoMode = iota
+	// any number of moves can be undone, as long as there are undos left
+	UnlimitedUndo
+)
+
+var undoModeNames = [...]string{
+	ClassicUndo:   "classic",
+	UnlimitedUndo: "unlimited",
+}
+
+func (m UndoMode) String() string {
+	if m < ClassicUndo || m > UnlimitedUndo {
+		return fmt.Sprintf("UndoMode(%d)", int(m))
+	}
+	return undoModeNames[m]
+}
+
+// ParseUndoMode is the inverse of UndoMode.String.
+func ParseUndoMode(s string) (UndoMode, error) {
+	for m, name := range undoModeNames {
+		if s == name {
+			return UndoMode(m), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid undo mode: %q, allowed values: classic, unlimited", s)
+}
+
+// an undone move, kept so that it can be redone
+type redoEntry struct {
+	state *stableState
+	move  Move
+}
+
+func (g *Game) UndoMode() UndoMode {
+	return g.undoMode
+}
+
+// SetUndoMode switches between classic and unlimited undos,
+// switching to classic mode forgets all but the last move.
+func (g *Game) SetUndoMode(mode UndoMode) {
+	g.undoMode = mode
+	g.trimUndoHistory()
+}
+
+// the maximum number of states worth keeping on the undo stack
+func (g *Game) undoDepth() int {
+	if g.undoMode == ClassicUndo {
+		return min(1, g.undosLeft)
+	}
+	return g.undosLeft
+}
+
+func (g *Game) trimUndoHistory() {
+	depth := g.undoDepth()
+	if n := len(g.undoStack) - depth; n > 0 {
+		g.freeStates = append(g.freeStates, g.undoStack[:n]...)
+		g.undoStack = append(g.undoStack[:0], g.undoStack[n:]...)
+	}
+	if g.undoMode == ClassicUndo && len(g.redoStack) > 1 {
+		g.redoStack = g.redoStack[len(g.redoStack)-1:]
+	}
+}
+
+// returns a state that can be overwritten
+func (g *Game) allocState() *stableState {
+	if n := len(g.freeStates); n > 0 {
+		s := g.freeStates[n-1]
+		g.freeStates = g.freeStates[:n-1]
+		return s
+	}
+	return newInitialState(g.Rows, g.Cols)
+}
+
+func (g *Game) pushUndoState(s *stableState) {
+	if g.undoDepth() == 0 {
+		g.freeStates = append(g.freeStates, s)
+		return
+	}
+	g.undoStack = append(g.undoStack, s)
+	g.trimUndoHistory()
+}
+
+func (g *Game) clearRedoStack() {
+	for _, entry := range g.redoStack {
+		g.freeStates = append(g.freeStates, entry.state)
+	}
+	g.redoStack = g.redoStack[:0]
+}
+
+func (g *Game) Undo() bool {
+	if g.Phase == Finished || g.quit || g.undosLeft == 0 || len(g.undoStack) == 0 {
+		return false
+	}
+
+	defer g.dispatch()
+	score := g.score
+	current := g.allocState()
+	current.deepCopyFrom(&g.stableState)
+	g.redoStack = append(g.redoStack, redoEntry{
+		state: current,
+		move:  g.history[len(g.history)-1],
+	})
+
+	prev := g.undoStack[len(g.undoStack)-1]
+	g.undoStack = g.undoStack[:len(g.undoStack)-1]
+	g.stableState.deepCopyFrom(prev)
+	g.freeStates = append(g.freeStates, prev)
+
+	g.history = g.history[:len(g.history)-1]
+	g.undosLeft--
+	g.stats.Undos++
+	g.lastAction = time.Now()
+	g.transition = Transition{}
+	g.trimUndoHistory()
+	g.emit(UndoApplied{Move: g.redoStack[len(g.redoStack)-1].move, UndosLeft: g.undosLeft})
+	if g.score != score {
+		g.emit(ScoreChanged{Score: g.score, Delta: g.score - score})
+	}
+	g.autosave()
+	return true
+}
+
+// Redo reapplies the last undone move, it doesn't cost an undo.
+// Undone moves can be redone only until the next push.
+func (g *Game) Redo() bool {
+	if g.Phase == Finished || g.quit || len(g.redoStack) == 0 {
+		return false
+	}
+
+	defer g.dispatch()
+	score := g.score
+	current := g.allocState()
+	current.deepCopyFrom(&g.stableState)
+	g.pushUndoState(current)
+
+	entry := g.redoStack[len(g.redoStack)-1]
+	g.redoStack = g.redoStack[:len(g.redoStack)-1]
+	g.stableState.deepCopyFrom(entry.state)
+	g.freeStates = append(g.freeStates, entry.state)
+
+	g.history = append(g.history, entry.move)
+	g.lastAction = time.Now()
+	g.transition = Transition{
+		Dir:   entry.move.Dir,
+		Tiles: current.board.Tiles(entry.move.Dir),
+		Spawn: entry.move.Spawn,
+	}
+	g.emitTransition(g.transition, score)
+	g.autosave()
+	return true
+}
+
+func (g *Game) CanUndo() bool {
+	return g.Phase != Finished && !g.quit && g.undosLeft > 0 && len(g.undoStack) > 0
+}
+
+func (g *Game) CanRedo() bool {
+	return g.Phase != Finished && !g.quit && len(g.redoStack) > 0
+}
@@ -0,0 +1,177 @@
+package core
+
+import "testing"
+
+// pushes in the first direction that moves a block, the test fails
+// if none does
+func pushAny(t *testing.T, g *Game) {
+	t.Helper()
+	for _, dir := range []Direction{Up, Left, Down, Right} {
+		if _, _, moved := g.Board().Move(dir); moved {
+			g.Push(dir)
+			return
+		}
+	}
+	t.Fatal("no moves left")
+}
+
+// plays n moves, and returns the board and the score after each,
+// starting with the current ones
+func playMoves(t *testing.T, g *Game, n int) (boards []Board, scores []int) {
+	t.Helper()
+	boards, scores = append(boards, g.Board()), append(scores, g.Score())
+	for i := 0; i < n; i++ {
+		pushAny(t, g)
+		boards, scores = append(boards, g.Board()), append(scores, g.Score())
+	}
+	return boards, scores
+}
+
+func checkState(t *testing.T, g *Game, board Board, score int, moves int) {
+	t.Helper()
+	if !g.Board().Equal(board) {
+		t.Errorf("board = %v, want %v", g.Board().Blocks(), board.Blocks())
+	}
+	if g.Score() != score {
+		t.Errorf("score = %d, want %d", g.Score(), score)
+	}
+	if g.Moves() != moves {
+		t.Errorf("moves = %d, want %d", g.Moves(), moves)
+	}
+}
+
+func TestClassicUndoNoTwoInARow(t *testing.T) {
+	g, _ := NewGameWithSeed("p", 4, 2048, 3, 1)
+	boards, scores := playMoves(t, g, 5)
+
+	if !g.Undo() {
+		t.Fatal("undo of the last move failed")
+	}
+	checkState(t, g, boards[4], scores[4], 4)
+	if g.CanUndo() || g.Undo() {
+		t.Error("two consecutive undos allowed in classic mode")
+	}
+	checkState(t, g, boards[4], scores[4], 4)
+	if g.UndosLeft() != 2 {
+		t.Errorf("undos left = %d, want 2", g.UndosLeft())
+	}
+
+	// the next push can be undone again
+	pushAny(t, g)
+	if !g.Undo() {
+		t.Error("undo after a push failed")
+	}
+}
+
+func TestUndoRedoUndoBudget(t *testing.T) {
+	for _, mode := range []UndoMode{ClassicUndo, UnlimitedUndo} {
+		g, _ := NewGameWithSeed("p", 4, 2048, 2, 1)
+		g.SetUndoMode(mode)
+		boards, scores := playMoves(t, g, 3)
+		spawn := g.History()[2].Spawn
+
+		if !g.Undo() {
+			t.Fatalf("%v: undo failed", mode)
+		}
+		if !g.Redo() {
+			t.Fatalf("%v: redo failed", mode)
+		}
+		checkState(t, g, boards[3], scores[3], 3)
+		if got := g.History()[2].Spawn; got != spawn {
+			t.Errorf("%v: redone move spawned %v, want %v", mode, got, spawn)
+		}
+		if g.UndosLeft() != 1 {
+			t.Errorf("%v: undos left = %d after undo and redo, want 1", mode, g.UndosLeft())
+		}
+
+		// the redone move can be undone, at the cost of another undo
+		if !g.Undo() {
+			t.Fatalf("%v: undo of the redone move failed", mode)
+		}
+		checkState(t, g, boards[2], scores[2], 2)
+		if g.UndosLeft() != 0 || g.CanUndo() || g.Undo() {
+			t.Errorf("%v: undo allowed without undos left", mode)
+		}
+
+		// redos are free
+		if !g.Redo() {
+			t.Errorf("%v: redo without undos left failed", mode)
+		}
+		checkState(t, g, boards[3], scores[3], 3)
+	}
+}
+
+func TestUnlimitedUndo(t *testing.T) {
+	g, _ := NewGameWithSeed("p", 4, 2048, 3, 1)
+	g.SetUndoMode(UnlimitedUndo)
+	boards, scores := playMoves(t, g, 5)
+
+	for i := 4; i >= 2; i-- {
+		if !g.Undo() {
+			t.Fatalf("undo back to move %d failed", i)
+		}
+		checkState(t, g, boards[i], scores[i], i)
+	}
+	if g.Undo() {
+		t.Error("undo allowed past the budget")
+	}
+	for i := 3; i <= 5; i++ {
+		if !g.Redo() {
+			t.Fatalf("redo of move %d failed", i)
+		}
+		checkState(t, g, boards[i], scores[i], i)
+	}
+	if g.Redo() {
+		t.Error("redo allowed with nothing undone")
+	}
+}
+
+func TestSetUndoModeTrims(t *testing.T) {
+	g, _ := NewGameWithSeed("p", 4, 2048, 5, 1)
+	g.SetUndoMode(UnlimitedUndo)
+	boards, scores := playMoves(t, g, 6)
+	g.Undo()
+	g.Undo()
+
+	// only the last move, and the last undone one, are kept
+	g.SetUndoMode(ClassicUndo)
+	if !g.Redo() {
+		t.Fatal("redo after switching to classic mode failed")
+	}
+	checkState(t, g, boards[5], scores[5], 5)
+	if g.CanRedo() || g.Redo() {
+		t.Error("more than one redo kept in classic mode")
+	}
+	if !g.Undo() {
+		t.Fatal("undo after switching to classic mode failed")
+	}
+	checkState(t, g, boards[4], scores[4], 4)
+	if g.CanUndo() || g.Undo() {
+		t.Error("more than one undo kept in classic mode")
+	}
+}
+
+func TestPushClearsRedo(t *testing.T) {
+	for _, mode := range []UndoMode{ClassicUndo, UnlimitedUndo} {
+		g, _ := NewGameWithSeed("p", 4, 2048, 3, 1)
+		g.SetUndoMode(mode)
+		playMoves(t, g, 3)
+		g.Undo()
+		if !g.CanRedo() {
+			t.Fatalf("%v: nothing to redo after an undo", mode)
+		}
+
+		pushAny(t, g)
+		if g.CanRedo() || g.Redo() {
+			t.Errorf("%v: redo allowed after a push", mode)
+		}
+	}
+}
+
+func TestNoUndos(t *testing.T) {
+	g, _ := NewGameWithSeed("p", 4, 2048, 0, 1)
+	playMoves(t, g, 2)
+	if g.CanUndo() || g.Undo() {
+		t.Error("undo allowed in a game without undos")
+	}
+}
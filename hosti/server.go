@@ -0,0 +1,372 @@
+// Package hosti serves 2048 games over HTTP, so that they can be played
+// (by humans or bots) from other machines.
+//
+// Every game lives in a session identified by an opaque id. All requests
+// and responses are JSON; every successful response carries the state
+// of the game after the command was executed.
+//
+//	POST   /games            create a game, body: {"player": "Bot", "rows": 4,
+//	                         "cols": 4, "target": 2048, "undos": 3, "seed": 42,
+//	                         "undoMode": "classic"} (every field is optional,
+//	                         "size" can be used instead of rows and cols)
+//	GET    /games/{id}       get the state of a game
+//	POST   /games/{id}/move  push the blocks, body: {"direction": "up"}
+//	                         where direction is one of up, down, left, right
+//	POST   /games/{id}/undo  undo the last move
+//	POST   /games/{id}/redo  redo the last undone move
+//	POST   /games/{id}/continue
+//	                         keep going after the game was won, the game then
+//	                         lasts until no moves remain
+//	DELETE /games/{id}       end the game and drop the session
+//
+// A game state looks like:
+//
+//	{
+//	  "id": "3f1c...",
+//	  "player": "Bot",
+//	  "rows": 4,
+//	  "cols": 4,
+//	  "target": 2048,
+//	  "seed": 42,
+//	  "board": [[0, 2, 0, 0], [0, 0, 0, 0], [0, 0, 4, 0], [0, 0, 0, 0]],
+//	  "score": 0,
+//	  "undosLeft": 3,
+//	  "outcome": "continue",
+//	  "ok": true,
+//	  "events": [
+//	    {"type": "TileMoved", "data": {"from": {"row": 0, "col": 1}, "to": {"row": 0, "col": 0}, "value": 2, "merged": false}},
+//	    {"type": "TileSpawned", "data": {"row": 3, "col": 2, "value": 2}}
+//	  ]
+//	}
+//
+// where outcome is one of continue, gameover and win, and ok reports
+// whether the command changed the board (a push that moves no blocks
+// and a rejected undo are not errors). Events lists what happened in the
+// game during the command, in order; the types of events are those of
+// package core (TileMoved, TileMerged, TileSpawned, ScoreChanged,
+// UndoApplied, PhaseChanged, GameWon and GameLost), where a phase is one
+// of notstarted, notfinished and finished.
+//
+// Errors are reported with a non-2xx status code and a body like
+// {"error": "no such game"}.
+//
+// Sessions that are idle for longer than SessionTimeout are dropped.
+package hosti
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cicovic-andrija/2048/core"
+)
+
+const (
+	SessionTimeout = 30 * time.Minute
+	MaxSessions    = 1024
+
+	maxRequestBody = 4096
+)
+
+type session struct {
+	mu       sync.Mutex
+	id       string
+	game     *core.Game
+	lastSeen time.Time
+	events   []eventJSON // events of the command being executed
+}
+
+type eventJSON struct {
+	Type string     `json:"type"`
+	Data core.Event `json:"data"`
+}
+
+func newSession(id string, game *core.Game) *session {
+	s := &session{id: id, game: game, lastSeen: time.Now()}
+	game.Subscribe(core.ObserverFunc(func(e core.Event) {
+		s.events = append(s.events, eventJSON{Type: e.Name(), Data: e})
+	}))
+	return s
+}
+
+type Server struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func NewServer() *Server {
+	return &Server{
+		sessions: make(map[string]*session),
+	}
+}
+
+// ListenAndServe serves hosted games on the given TCP address.
+func ListenAndServe(addr string) error {
+	srv := NewServer()
+	go srv.expireSessions()
+	return http.ListenAndServe(addr, srv)
+}
+
+type newGameRequest struct {
+	Player   string `json:"player"`
+	Size     int    `json:"size"`
+	Rows     int    `json:"rows"`
+	Cols     int    `json:"cols"`
+	Target   int    `json:"target"`
+	Undos    *int   `json:"undos"`
+	Seed     int64  `json:"seed"`
+	UndoMode string `json:"undoMode"`
+}
+
+type moveRequest struct {
+	Direction string `json:"direction"`
+}
+
+type gameState struct {
+	ID        string      `json:"id"`
+	Player    string      `json:"player"`
+	Rows      int         `json:"rows"`
+	Cols      int         `json:"cols"`
+	Target    int         `json:"target"`
+	Seed      int64       `json:"seed"`
+	Board     [][]int     `json:"board"`
+	Score     int         `json:"score"`
+	UndosLeft int         `json:"undosLeft"`
+	Outcome   string      `json:"outcome"`
+	OK        bool        `json:"ok"`
+	Events    []eventJSON `json:"events,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *session) state(ok bool) *gameState {
+	g := s.game
+	return &gameState{
+		ID:        s.id,
+		Player:    g.Player,
+		Rows:      g.Rows,
+		Cols:      g.Cols,
+		Target:    g.Target,
+		Seed:      g.Seed(),
+		Board:     g.Board().Blocks(),
+		Score:     g.Score(),
+		UndosLeft: g.UndosLeft(),
+		Outcome:   g.Outcome().String(),
+		OK:        ok,
+	}
+}
+
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	if parts[0] != "games" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	if len(parts) == 1 {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		srv.createGame(w, r)
+		return
+	}
+
+	sess := srv.lookup(parts[1])
+	if sess == nil {
+		writeError(w, http.StatusNotFound, errors.New("no such game"))
+		return
+	}
+
+	command := ""
+	if len(parts) == 3 {
+		command = parts[2]
+	}
+
+	switch {
+	case command == "" && r.Method == http.MethodGet:
+		sess.do(w, func(g *core.Game) (bool, error) { return true, nil })
+	case command == "" && r.Method == http.MethodDelete:
+		srv.drop(sess.id)
+		w.WriteHeader(http.StatusNoContent)
+	case command == "move" && r.Method == http.MethodPost:
+		var req moveRequest
+		if err := readJSON(w, r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		dir, err := core.ParseDirection(req.Direction)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		sess.apply(w, core.PushTo(dir))
+	case command == "undo" && r.Method == http.MethodPost:
+		sess.apply(w, core.Action{Kind: core.UndoAction})
+	case command == "redo" && r.Method == http.MethodPost:
+		sess.apply(w, core.Action{Kind: core.RedoAction})
+	case command == "continue" && r.Method == http.MethodPost:
+		sess.apply(w, core.Action{Kind: core.KeepGoingAction})
+	case command == "" || command == "move" || command == "undo" || command == "redo" || command == "continue":
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+func (srv *Server) createGame(w http.ResponseWriter, r *http.Request) {
+	req := newGameRequest{
+		Player:   "Player",
+		Size:     4,
+		Target:   2048,
+		UndoMode: "classic",
+	}
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	mode, err := core.ParseUndoMode(req.UndoMode)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	undos := 3
+	if req.Undos != nil {
+		undos = *req.Undos
+	}
+
+	if req.Rows == 0 {
+		req.Rows = req.Size
+	}
+	if req.Cols == 0 {
+		req.Cols = req.Size
+	}
+	if req.Seed == 0 {
+		req.Seed = time.Now().UnixNano()
+	}
+
+	game, err := core.NewRectGame(req.Player, req.Rows, req.Cols, req.Target, undos, req.Seed)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	game.SetUndoMode(mode)
+
+	id, err := newSessionID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	sess := newSession(id, game)
+	// taken before the session is published, other requests may then use it
+	state := sess.state(true)
+
+	srv.mu.Lock()
+	if len(srv.sessions) >= MaxSessions {
+		srv.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, errors.New("too many games in progress"))
+		return
+	}
+	srv.sessions[id] = sess
+	srv.mu.Unlock()
+
+	w.Header().Set("Location", "/games/"+id)
+	writeJSON(w, http.StatusCreated, state)
+}
+
+func (srv *Server) lookup(id string) *session {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.sessions[id]
+}
+
+func (srv *Server) drop(id string) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	delete(srv.sessions, id)
+}
+
+func (srv *Server) expireSessions() {
+	for range time.Tick(SessionTimeout / 10) {
+		deadline := time.Now().Add(-SessionTimeout)
+		srv.mu.Lock()
+		for id, sess := range srv.sessions {
+			sess.mu.Lock()
+			if sess.lastSeen.Before(deadline) {
+				delete(srv.sessions, id)
+			}
+			sess.mu.Unlock()
+		}
+		srv.mu.Unlock()
+	}
+}
+
+// do runs a command against the game of the session and replies with
+// the resulting state of the game
+func (s *session) do(w http.ResponseWriter, command func(*core.Game) (bool, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastSeen = time.Now()
+	s.events = nil
+	ok, err := command(s.game)
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	state := s.state(ok)
+	state.Events = s.events
+	writeJSON(w, http.StatusOK, state)
+}
+
+// apply lets the remote player act in the game of the session,
+// a push is rejected once the game is finished
+func (s *session) apply(w http.ResponseWriter, a core.Action) {
+	s.do(w, func(g *core.Game) (bool, error) {
+		if a.Kind == core.PushAction && g.Phase == core.Finished {
+			return false, errors.New("game is finished")
+		}
+		return g.Apply(a), nil
+	})
+}
+
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// an empty body is accepted and leaves v untouched
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &errorResponse{Error: err.Error()})
+}
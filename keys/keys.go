@@ -0,0 +1,318 @@
+// Package keys maps the keys of the user interfaces to the actions
+// of the player.
+package keys
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cicovic-andrija/2048/core"
+)
+
+type Action int
+
+const (
+	Up Action = iota
+	Down
+	Left
+	Right
+	Undo
+	Redo
+	Hint
+	NewGame
+	Quit
+	Stats // show or hide the statistics
+	Theme // switch to the next color theme
+	Help  // show the key bindings
+)
+
+var actionNames = [...]string{
+	Up:      "up",
+	Down:    "down",
+	Left:    "left",
+	Right:   "right",
+	Undo:    "undo",
+	Redo:    "redo",
+	Hint:    "hint",
+	NewGame: "newgame",
+	Quit:    "quit",
+	Stats:   "stats",
+	Theme:   "theme",
+	Help:    "help",
+}
+
+// Actions lists all the actions, in the order they are described in.
+var Actions = []Action{Up, Down, Left, Right, Undo, Redo, Hint, NewGame, Quit, Stats, Theme, Help}
+
+// actions that must have a key in every interface
+var required = []Action{Up, Down, Left, Right, Quit}
+
+func (a Action) String() string {
+	if a < Up || a > Help {
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+	return actionNames[a]
+}
+
+var actionDescriptions = [...]string{
+	Up:      "push up",
+	Down:    "push down",
+	Left:    "push left",
+	Right:   "push right",
+	Undo:    "undo the last move",
+	Redo:    "redo the undone move",
+	Hint:    "show the best move",
+	NewGame: "start a new game",
+	Quit:    "quit",
+	Stats:   "show or hide the statistics",
+	Theme:   "switch to the next color theme",
+	Help:    "show the key bindings",
+}
+
+// Description returns what the action does, as shown in the help.
+func (a Action) Description() string {
+	if a < Up || a > Help {
+		return a.String()
+	}
+	return actionDescriptions[a]
+}
+
+// GameAction returns the action on the game triggered by the key action,
+// ok is false for the actions handled by the interface itself.
+func GameAction(a Action) (ga core.Action, ok bool) {
+	switch a {
+	case Up:
+		return core.PushTo(core.Up), true
+	case Down:
+		return core.PushTo(core.Down), true
+	case Left:
+		return core.PushTo(core.Left), true
+	case Right:
+		return core.PushTo(core.Right), true
+	case Undo:
+		return core.Action{Kind: core.UndoAction}, true
+	case Redo:
+		return core.Action{Kind: core.RedoAction}, true
+	case NewGame:
+		return core.Action{Kind: core.NewGameAction}, true
+	case Quit:
+		return core.Action{Kind: core.QuitAction}, true
+	default:
+		return ga, false
+	}
+}
+
+// ParseAction is the inverse of Action.String.
+func ParseAction(s string) (Action, error) {
+	for a, name := range actionNames {
+		if s == name {
+			return Action(a), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid action %q, must be one of %s", s, strings.Join(actionNames[:], ", "))
+}
+
+func (a Action) MarshalText() ([]byte, error) {
+	if a < Up || a > Help {
+		return nil, fmt.Errorf("invalid action: %d", int(a))
+	}
+	return []byte(a.String()), nil
+}
+
+func (a *Action) UnmarshalText(text []byte) error {
+	action, err := ParseAction(string(text))
+	if err != nil {
+		return err
+	}
+	*a = action
+	return nil
+}
+
+// Bindings maps actions to the keys that trigger them. A key is a single
+// character, or the name of a special key such as "Esc" or "Ctrl+U".
+type Bindings map[Action][]string
+
+// Normalize returns the form in which keys are compared: characters are
+// case-insensitive, as are names, where "Ctrl-U" is the same as "Ctrl+U".
+func Normalize(key string) string {
+	if utf8.RuneCountInString(key) == 1 {
+		return strings.ToLower(key)
+	}
+	return strings.ReplaceAll(strings.ToLower(key), "-", "+")
+}
+
+func (b Bindings) Clone() Bindings {
+	clone := make(Bindings, len(b))
+	for a, keys := range b {
+		clone[a] = append([]string(nil), keys...)
+	}
+	return clone
+}
+
+// Merge replaces the keys of the actions bound in other.
+func (b Bindings) Merge(other Bindings) {
+	for a, keys := range other {
+		b[a] = append([]string(nil), keys...)
+	}
+}
+
+// Set parses a binding in the form action=key[,key...] and replaces the
+// keys of the action, an empty list of keys unbinds it.
+func (b Bindings) Set(spec string) error {
+	i := strings.IndexByte(spec, '=')
+	if i < 0 {
+		return fmt.Errorf("invalid key binding %q, must be action=key[,key...]", spec)
+	}
+	a, err := ParseAction(strings.TrimSpace(spec[:i]))
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	if list := spec[i+1:]; list != "" {
+		for _, key := range strings.Split(list, ",") {
+			if key = strings.TrimSpace(key); key == "" {
+				return fmt.Errorf("invalid key binding %q: empty key", spec)
+			}
+			keys = append(keys, key)
+		}
+	}
+	b[a] = keys
+	return nil
+}
+
+// Check validates the bindings of an interface which supports the given
+// actions and keys (valid reports whether the interface knows a key).
+// Reserved keys have a fixed meaning and can't be bound.
+func (b Bindings) Check(actions []Action, valid func(key string) bool, reserved ...string) error {
+	supported := make(map[Action]bool, len(actions))
+	for _, a := range actions {
+		supported[a] = true
+	}
+	for _, a := range required {
+		if supported[a] && len(b[a]) == 0 {
+			return fmt.Errorf("no key bound to %v", a)
+		}
+	}
+
+	bound := make(map[string]Action)
+	for _, key := range reserved {
+		bound[Normalize(key)] = -1
+	}
+	for _, a := range Actions {
+		if len(b[a]) > 0 && !supported[a] {
+			return fmt.Errorf("action %v is not available in this interface", a)
+		}
+		for _, key := range b[a] {
+			if !valid(key) {
+				return fmt.Errorf("unknown key %q bound to %v", key, a)
+			}
+			other, ok := bound[Normalize(key)]
+			switch {
+			case !ok:
+				bound[Normalize(key)] = a
+			case other < 0:
+				return fmt.Errorf("key %q is reserved and can't be bound to %v", key, a)
+			case other == a:
+				return fmt.Errorf("key %q is bound to %v twice", key, a)
+			default:
+				return fmt.Errorf("key %q is bound to both %v and %v", key, other, a)
+			}
+		}
+	}
+	return nil
+}
+
+// Index returns the actions by normalized key, it assumes the bindings
+// have been checked.
+func (b Bindings) Index() map[string]Action {
+	index := make(map[string]Action)
+	for a, keys := range b {
+		for _, key := range keys {
+			index[Normalize(key)] = a
+		}
+	}
+	return index
+}
+
+// Label returns the keys of the action as they are shown to the player.
+func (b Bindings) Label(a Action) string {
+	if len(b[a]) == 0 {
+		return "unbound"
+	}
+	return strings.Join(b[a], "/")
+}
+
+// Keymap holds the bindings of both user interfaces.
+type Keymap struct {
+	Term Bindings `json:"term,omitempty"` // terminal graphics
+	Text Bindings `json:"text,omitempty"`
+}
+
+// DefaultKeymap returns the bindings used unless configured otherwise.
+func DefaultKeymap() Keymap {
+	return Keymap{
+		Term: Bindings{
+			Up:      {"Up"},
+			Down:    {"Down"},
+			Left:    {"Left"},
+			Right:   {"Right"},
+			Undo:    {"Ctrl+U"},
+			Redo:    {"Ctrl+R"},
+			Hint:    {"h"},
+			NewGame: {"n"},
+			Quit:    {"Esc"},
+			Stats:   {"s"},
+			Theme:   {"c"},
+			Help:    {"?"},
+		},
+		Text: Bindings{
+			Up:      {"w", "k"},
+			Down:    {"s", "j"},
+			Left:    {"a", "h"},
+			Right:   {"d", "l"},
+			Undo:    {"u"},
+			Redo:    {"r"},
+			Hint:    {"t"},
+			NewGame: {"n"},
+			Quit:    {"q"},
+			Help:    {"?"},
+		},
+	}
+}
+
+// Merge replaces the keys of the actions bound in other.
+func (m *Keymap) Merge(other Keymap) {
+	m.Term.Merge(other.Term)
+	m.Text.Merge(other.Text)
+}
+
+// LoadKeymap reads the bindings from a JSON file, such as
+// {"term": {"undo": ["u", "Ctrl+U"]}, "text": {"quit": ["x"]}},
+// the actions missing from the file keep their default keys.
+func LoadKeymap(path string) (Keymap, error) {
+	m := DefaultKeymap()
+	loaded, err := ReadKeymap(path)
+	if err != nil {
+		return m, err
+	}
+	m.Merge(loaded)
+	return m, nil
+}
+
+// ReadKeymap reads the bindings from a JSON file like LoadKeymap, but only
+// the actions in the file are bound, so they can be merged over other ones.
+func ReadKeymap(path string) (Keymap, error) {
+	var m Keymap
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("invalid key bindings file %s: %v", path, err)
+	}
+	return m, nil
+}
@@ -0,0 +1,102 @@
+package keys
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// knows the characters and the keys of the default bindings
+func testKey(key string) bool {
+	switch Normalize(key) {
+	case "up", "down", "left", "right", "esc", "enter", "ctrl+u", "ctrl+r", "ctrl+c":
+		return true
+	}
+	return utf8.RuneCountInString(key) == 1
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		specs    []string
+		actions  []Action
+		reserved []string
+		err      string // part of the error, none if empty
+	}{
+		{name: "defaults"},
+		{name: "rebound", specs: []string{"undo=z", "redo=Ctrl-U"}},
+		{name: "same key for two actions", specs: []string{"hint=n"}, err: `key "n" is bound to both hint and newgame`},
+		{name: "same key differently written", specs: []string{"redo=ctrl-u"}, err: `key "ctrl-u" is bound to both undo and redo`},
+		{name: "same key twice", specs: []string{"hint=h,H"}, err: `key "H" is bound to hint twice`},
+		{name: "reserved key", specs: []string{"quit=Ctrl+C"}, reserved: []string{"Ctrl+C"}, err: `key "Ctrl+C" is reserved and can't be bound to quit`},
+		{name: "reserved escape", reserved: []string{"Enter", "Esc"}, err: `key "Esc" is reserved and can't be bound to quit`},
+		{name: "empty required binding", specs: []string{"left="}, err: "no key bound to left"},
+		{name: "empty optional binding", specs: []string{"hint="}},
+		{name: "unknown key", specs: []string{"hint=F13"}, err: `unknown key "F13" bound to hint`},
+		{
+			name:    "unsupported action",
+			actions: []Action{Up, Down, Left, Right, Quit},
+			err:     "is not available in this interface",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := DefaultKeymap().Term
+			for _, spec := range tt.specs {
+				if err := b.Set(spec); err != nil {
+					t.Fatal(err)
+				}
+			}
+			actions := tt.actions
+			if actions == nil {
+				actions = Actions
+			}
+			err := b.Check(actions, testKey, tt.reserved...)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("Check() = %v, want no error", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("Check() = %v, want an error with %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		spec string
+		keys []string
+		err  string
+	}{
+		{spec: "undo=u", keys: []string{"u"}},
+		{spec: " undo = u , Ctrl+U ", keys: []string{"u", "Ctrl+U"}},
+		{spec: "undo=", keys: nil},
+		{spec: "undo", err: "must be action=key"},
+		{spec: "undo=u,", err: "empty key"},
+		{spec: "undo=u,,z", err: "empty key"},
+		{spec: "jump=j", err: `invalid action "jump"`},
+		{spec: "=j", err: `invalid action ""`},
+	}
+
+	for _, tt := range tests {
+		b := DefaultKeymap().Term
+		err := b.Set(tt.spec)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Set(%q) = %v, want an error with %q", tt.spec, err, tt.err)
+			}
+			if len(b[Undo]) != 1 || b[Undo][0] != "Ctrl+U" {
+				t.Errorf("Set(%q) failed, but changed undo to %q", tt.spec, b[Undo])
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Set(%q) = %v", tt.spec, err)
+			continue
+		}
+		if got := b[Undo]; strings.Join(got, ",") != strings.Join(tt.keys, ",") {
+			t.Errorf("Set(%q) bound undo to %q, want %q", tt.spec, got, tt.keys)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package sim
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Formats lists the names accepted by Report.Write.
+var Formats = []string{"text", "json", "csv"}
+
+// CheckFormat reports an error if format is not one of Formats.
+func CheckFormat(format string) error {
+	for _, f := range Formats {
+		if strings.EqualFold(format, f) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid format %q, must be one of %s", format, strings.Join(Formats, ", "))
+}
+
+// Write writes the report in the given format.
+func (r *Report) Write(w io.Writer, format string) error {
+	if err := CheckFormat(format); err != nil {
+		return err
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case "csv":
+		return r.writeCSV(w)
+	default:
+		return r.writeText(w)
+	}
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintf(tw, "Strategy %s, %d games on a %dx%d board, seed %d, took %v\n",
+		r.Strategy, r.Games, r.Rows, r.Cols, r.Seed, r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(tw, "Average moves %.1f, average score %.1f\n", r.AverageMoves, r.AverageScore)
+
+	fmt.Fprintf(tw, "\nTarget\tWins\tWin rate\t\n")
+	for _, wr := range r.WinRates {
+		fmt.Fprintf(tw, "%d\t%d\t%.1f%%\t\n", wr.Target, wr.Wins, 100*wr.Rate)
+	}
+
+	fmt.Fprintf(tw, "\nPercentile\tScore\t\n")
+	for _, p := range r.Percentiles {
+		fmt.Fprintf(tw, "p%d\t%d\t\n", p.Percentile, p.Score)
+	}
+
+	fmt.Fprintf(tw, "\nHighest block\tGames\t\n")
+	for _, t := range r.MaxTiles {
+		fmt.Fprintf(tw, "%d\t%d\t\n", t.Tile, t.Games)
+	}
+
+	return tw.Flush()
+}
+
+// every row is a metric, an optional key, and the value,
+// so that the whole report fits in one table
+func (r *Report) writeCSV(w io.Writer) error {
+	itoa := strconv.Itoa
+	ftoa := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	records := [][]string{
+		{"metric", "key", "value"},
+		{"strategy", "", r.Strategy},
+		{"games", "", itoa(r.Games)},
+		{"rows", "", itoa(r.Rows)},
+		{"cols", "", itoa(r.Cols)},
+		{"seed", "", strconv.FormatInt(r.Seed, 10)},
+		{"duration_seconds", "", ftoa(r.Duration.Seconds())},
+		{"average_moves", "", ftoa(r.AverageMoves)},
+		{"average_score", "", ftoa(r.AverageScore)},
+	}
+	for _, wr := range r.WinRates {
+		records = append(records, []string{"win_rate", itoa(wr.Target), ftoa(wr.Rate)})
+	}
+	for _, p := range r.Percentiles {
+		records = append(records, []string{"score_percentile", itoa(p.Percentile), itoa(p.Score)})
+	}
+	for _, t := range r.MaxTiles {
+		records = append(records, []string{"max_tile", itoa(t.Tile), itoa(t.Games)})
+	}
+
+	cw := csv.NewWriter(w)
+	cw.WriteAll(records)
+	return cw.Error()
+}
@@ -0,0 +1,194 @@
+// Package sim plays batches of 2048 games without a user interface,
+// using one of several strategies, and reports statistics about them.
+package sim
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cicovic-andrija/2048/core"
+)
+
+// Config describes a batch of games.
+type Config struct {
+	Games    int    // number of games to play
+	Strategy string // name of the strategy, see Strategies
+	Rows     int
+	Cols     int
+	Target   int
+	Seed     int64 // seed of the first game, the i-th game uses Seed+i
+	Workers  int   // number of games played in parallel, 0 for one per CPU
+}
+
+// Result summarizes a single game.
+type Result struct {
+	Seed    int64
+	Score   int
+	Highest int
+	Moves   int
+}
+
+// Run plays the games of the batch and returns the report about them.
+// Games are not stopped when they are won, they last until no moves remain.
+func Run(cfg Config) (*Report, error) {
+	if cfg.Games < 1 {
+		return nil, fmt.Errorf("invalid number of games %d", cfg.Games)
+	}
+	if _, err := NewStrategy(cfg.Strategy, 0); err != nil {
+		return nil, err
+	}
+	if _, err := core.NewRectGame("sim", cfg.Rows, cfg.Cols, cfg.Target, 0, cfg.Seed); err != nil {
+		return nil, err
+	}
+
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, cfg.Games)
+
+	var (
+		start   = time.Now()
+		results = make([]Result, cfg.Games)
+		games   = make(chan int)
+		wg      sync.WaitGroup
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range games {
+				results[i] = play(cfg, cfg.Seed+int64(i))
+			}
+		}()
+	}
+	for i := 0; i < cfg.Games; i++ {
+		games <- i
+	}
+	close(games)
+	wg.Wait()
+
+	return newReport(cfg, results, time.Since(start)), nil
+}
+
+// the configuration is validated by Run
+func play(cfg Config, seed int64) Result {
+	g, _ := core.NewRectGame("sim", cfg.Rows, cfg.Cols, cfg.Target, 0, seed)
+	next, _ := NewStrategy(cfg.Strategy, seed)
+	core.Play(g, core.Bot(next), nil)
+	return Result{
+		Seed:    seed,
+		Score:   g.Score(),
+		Highest: g.HighestBlock(),
+		Moves:   g.Moves(),
+	}
+}
+
+// Report is the statistics about a batch of games.
+type Report struct {
+	Strategy     string        `json:"strategy"`
+	Games        int           `json:"games"`
+	Rows         int           `json:"rows"`
+	Cols         int           `json:"cols"`
+	Seed         int64         `json:"seed"`
+	Duration     time.Duration `json:"durationNs"`
+	AverageMoves float64       `json:"averageMoves"`
+	AverageScore float64       `json:"averageScore"`
+	WinRates     []WinRate     `json:"winRates"`
+	Percentiles  []Percentile  `json:"scorePercentiles"`
+	MaxTiles     []TileCount   `json:"maxTiles"`
+}
+
+// WinRate is the share of games in which the target block was reached.
+type WinRate struct {
+	Target int     `json:"target"`
+	Wins   int     `json:"wins"`
+	Rate   float64 `json:"rate"`
+}
+
+type Percentile struct {
+	Percentile int `json:"percentile"`
+	Score      int `json:"score"`
+}
+
+// TileCount is the number of games that ended with the given highest block.
+type TileCount struct {
+	Tile  int `json:"tile"`
+	Games int `json:"games"`
+}
+
+var percentiles = []int{0, 10, 25, 50, 75, 90, 99, 100}
+
+func newReport(cfg Config, results []Result, elapsed time.Duration) *Report {
+	r := &Report{
+		Strategy: cfg.Strategy,
+		Games:    len(results),
+		Rows:     cfg.Rows,
+		Cols:     cfg.Cols,
+		Seed:     cfg.Seed,
+		Duration: elapsed,
+	}
+
+	scores := make([]int, 0, len(results))
+	tiles := make(map[int]int)
+	moves, total := 0, 0
+	for _, res := range results {
+		scores = append(scores, res.Score)
+		tiles[res.Highest]++
+		moves += res.Moves
+		total += res.Score
+	}
+	r.AverageMoves = float64(moves) / float64(len(results))
+	r.AverageScore = float64(total) / float64(len(results))
+
+	for target := core.MinTarget; target <= core.MaxTarget; target *= 2 {
+		wins := 0
+		for _, res := range results {
+			if res.Highest >= target {
+				wins++
+			}
+		}
+		r.WinRates = append(r.WinRates, WinRate{
+			Target: target,
+			Wins:   wins,
+			Rate:   float64(wins) / float64(len(results)),
+		})
+	}
+
+	// nearest-rank percentiles
+	sort.Ints(scores)
+	for _, p := range percentiles {
+		rank := (p*len(scores) + 99) / 100
+		r.Percentiles = append(r.Percentiles, Percentile{
+			Percentile: p,
+			Score:      scores[max(rank-1, 0)],
+		})
+	}
+
+	for tile, games := range tiles {
+		r.MaxTiles = append(r.MaxTiles, TileCount{tile, games})
+	}
+	sort.Slice(r.MaxTiles, func(i, j int) bool {
+		return r.MaxTiles[i].Tile < r.MaxTiles[j].Tile
+	})
+
+	return r
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,78 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/cicovic-andrija/2048/core"
+	"github.com/cicovic-andrija/2048/solver"
+)
+
+// Strategies lists the names accepted by NewStrategy.
+var Strategies = []string{"random", "corner", "greedy", "expectimax"}
+
+// order in which the corner strategy tries the directions,
+// keeping the largest blocks in the bottom left corner
+var cornerOrder = [...]core.Direction{core.Down, core.Left, core.Right, core.Up}
+
+// NewStrategy returns the strategy with the given name. Strategies keep
+// state between moves, so every goroutine must use its own strategy.
+func NewStrategy(name string, seed int64) (core.MoveFunc, error) {
+	switch strings.ToLower(name) {
+	case "random":
+		return randomStrategy(rand.New(rand.NewSource(seed))), nil
+	case "corner":
+		return cornerStrategy, nil
+	case "greedy":
+		return greedyStrategy, nil
+	case "expectimax":
+		return solver.New(solver.DefaultDepth).BestMove, nil
+	default:
+		return nil, fmt.Errorf("invalid strategy %q, must be one of %s", name, strings.Join(Strategies, ", "))
+	}
+}
+
+// plays a random move out of the ones that move a block
+func randomStrategy(rng *rand.Rand) core.MoveFunc {
+	return func(v core.View) (core.Direction, bool) {
+		var valid []core.Direction
+		for _, dir := range cornerOrder {
+			if _, _, moved := v.Board().Move(dir); moved {
+				valid = append(valid, dir)
+			}
+		}
+		if len(valid) == 0 {
+			return 0, false
+		}
+		return valid[rng.Intn(len(valid))], true
+	}
+}
+
+// plays the first move, in cornerOrder, that moves a block
+func cornerStrategy(v core.View) (core.Direction, bool) {
+	for _, dir := range cornerOrder {
+		if _, _, moved := v.Board().Move(dir); moved {
+			return dir, true
+		}
+	}
+	return 0, false
+}
+
+// plays the move that scores the most points, leaving the most empty
+// cells on a tie, and falling back to cornerOrder on a further tie
+func greedyStrategy(v core.View) (dir core.Direction, ok bool) {
+	bestGained, bestEmpty := -1, -1
+	for _, d := range cornerOrder {
+		next, gained, moved := v.Board().Move(d)
+		if !moved {
+			continue
+		}
+		empty := len(next.EmptyCells())
+		if gained > bestGained || (gained == bestGained && empty > bestEmpty) {
+			dir, ok = d, true
+			bestGained, bestEmpty = gained, empty
+		}
+	}
+	return dir, ok
+}
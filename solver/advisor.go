@@ -0,0 +1,44 @@
+package solver
+
+import (
+	"github.com/cicovic-andrija/2048/core"
+)
+
+// DirectionAdvice describes what a move in one direction would do.
+type DirectionAdvice struct {
+	Dir    core.Direction
+	Valid  bool    // whether any block would move
+	Gained int     // points scored by the move itself
+	Value  float64 // expected value of the move according to the search
+}
+
+type Advice struct {
+	Best       core.Direction // meaningful only if Any is true
+	Any        bool           // whether any move is possible
+	Directions [4]DirectionAdvice
+}
+
+// Advise evaluates every possible move of the game, the game itself
+// is left untouched.
+func (s *Solver) Advise(v core.View) Advice {
+	var (
+		advice    Advice
+		bestValue float64
+	)
+
+	values, moved := s.Evaluate(v)
+	for i, dir := range directions {
+		_, gained, _ := v.Board().Move(dir)
+		advice.Directions[i] = DirectionAdvice{
+			Dir:    dir,
+			Valid:  moved[i],
+			Gained: gained,
+			Value:  values[i],
+		}
+		if moved[i] && (!advice.Any || values[i] > bestValue) {
+			advice.Best, advice.Any, bestValue = dir, true, values[i]
+		}
+	}
+
+	return advice
+}
@@ -0,0 +1,276 @@
+// Package solver plays 2048 with an expectimax search, which alternates
+// the player's moves (max nodes) with the random block spawns (chance nodes)
+// weighted by the probability of a 2 or a 4 being spawned.
+package solver
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/cicovic-andrija/2048/core"
+)
+
+const (
+	DefaultDepth = 3
+
+	// chance nodes less likely than this are not expanded further
+	minProbability = 1e-4
+
+	// heuristic weights
+	lostPenalty     = 200000.0
+	emptyWeight     = 270.0
+	mergesWeight    = 700.0
+	sumPower        = 3.5
+	sumWeight       = 11.0
+	monotonicPower  = 4.0
+	monotonicWeight = 47.0
+)
+
+var directions = [...]core.Direction{core.Up, core.Left, core.Right, core.Down}
+
+// powers of ranks used by the heuristic, precomputed since the
+// heuristic is evaluated for every leaf of the search
+var sumPowers, monotonicPowers [64]float64
+
+// heuristic of every row of a core.Bitboard
+var rowHeuristic [1 << 16]float64
+
+func init() {
+	for r := range sumPowers {
+		sumPowers[r] = math.Pow(float64(r), sumPower)
+		monotonicPowers[r] = math.Pow(float64(r), monotonicPower)
+	}
+
+	line := make([]uint8, core.BitboardSize)
+	for row := range rowHeuristic {
+		for k := range line {
+			line[k] = uint8(row>>(4*k)) & 0xf
+		}
+		rowHeuristic[row] = lineHeuristic(line)
+	}
+}
+
+type Solver struct {
+	Depth int // number of moves to look ahead
+
+	cache map[interface{}]cacheEntry
+}
+
+type cacheEntry struct {
+	depth int
+	value float64
+}
+
+func New(depth int) *Solver {
+	if depth < 1 {
+		depth = DefaultDepth
+	}
+	return &Solver{Depth: depth}
+}
+
+// BestMove returns the direction with the highest expected value,
+// ok is false if no move is possible.
+func (s *Solver) BestMove(v core.View) (dir core.Direction, ok bool) {
+	values, moved := s.Evaluate(v)
+	best := math.Inf(-1)
+	for i, d := range directions {
+		if moved[i] && values[i] > best {
+			dir, best, ok = d, values[i], true
+		}
+	}
+	return dir, ok
+}
+
+// Evaluate returns the expected value of every move in the order of
+// directions, together with whether the move is possible at all.
+func (s *Solver) Evaluate(v core.View) (values [4]float64, moved [4]bool) {
+	s.cache = make(map[interface{}]cacheEntry)
+	root := position(v)
+	depth := s.depthFor(root)
+	for i, dir := range directions {
+		next, gained, ok := root.Moved(dir)
+		if !ok {
+			continue
+		}
+		values[i] = float64(gained) + s.chanceNode(next, depth-1, 1.0)
+		moved[i] = true
+	}
+	s.cache = nil
+	return values, moved
+}
+
+// 4x4 games are searched on a bitboard, which is much faster
+func position(v core.View) core.Position {
+	b := v.Board()
+	if x, err := core.BitboardOf(b); err == nil {
+		return x
+	}
+	return b
+}
+
+// searches deeper when the board is crowded, since there are
+// fewer spawns to consider and every move matters more
+func (s *Solver) depthFor(b core.Position) int {
+	depth := s.Depth
+	if n := len(b.EmptyCells()); n <= 4 {
+		depth++
+	} else if n > 8 && depth > 2 {
+		depth--
+	}
+	return depth
+}
+
+func (s *Solver) maxNode(b core.Position, depth int, prob float64) float64 {
+	best, anyMoved := 0.0, false
+	for _, dir := range directions {
+		next, gained, moved := b.Moved(dir)
+		if !moved {
+			continue
+		}
+		value := float64(gained) + s.chanceNode(next, depth-1, prob)
+		if !anyMoved || value > best {
+			best, anyMoved = value, true
+		}
+	}
+	if !anyMoved {
+		return -lostPenalty
+	}
+	return best
+}
+
+func (s *Solver) chanceNode(b core.Position, depth int, prob float64) float64 {
+	if depth <= 0 || prob < minProbability {
+		return heuristic(b)
+	}
+
+	key := key(b)
+	if entry, ok := s.cache[key]; ok && entry.depth >= depth {
+		return entry.value
+	}
+
+	empty := b.EmptyCells()
+	if len(empty) == 0 {
+		return heuristic(b)
+	}
+
+	pfour := core.BlockFourProbability
+	prob /= float64(len(empty))
+	value := 0.0
+	for _, cell := range empty {
+		value += (1 - pfour) * s.maxNode(b.Spawned(cell, 2), depth, prob*(1-pfour))
+		value += pfour * s.maxNode(b.Spawned(cell, 4), depth, prob*pfour)
+	}
+	value /= float64(len(empty))
+
+	s.cache[key] = cacheEntry{depth: depth, value: value}
+	return value
+}
+
+// heuristic scores a position by looking at every row and column:
+// empty cells and possible merges are good, large blocks that are not
+// ordered monotonically along the line are bad
+func heuristic(b core.Position) float64 {
+	if x, ok := b.(core.Bitboard); ok {
+		t := x.Transpose()
+		score := 0.0
+		for i := 0; i < core.BitboardSize; i++ {
+			score += rowHeuristic[x.Row(i)] + rowHeuristic[t.Row(i)]
+		}
+		return score
+	}
+
+	rows, cols := b.Rows(), b.Cols()
+	ranks := make([]uint8, rows*cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			ranks[i*cols+j] = rank(b.Block(i, j))
+		}
+	}
+
+	score := 0.0
+	line := make([]uint8, 0, max(rows, cols))
+	for i := 0; i < rows; i++ {
+		score += lineHeuristic(ranks[i*cols : (i+1)*cols])
+	}
+	for j := 0; j < cols; j++ {
+		line = line[:0]
+		for i := 0; i < rows; i++ {
+			line = append(line, ranks[i*cols+j])
+		}
+		score += lineHeuristic(line)
+	}
+	return score
+}
+
+// log2 of the block, 0 for no block
+func rank(block int) uint8 {
+	if block == 0 {
+		return 0
+	}
+	return uint8(bits.TrailingZeros(uint(block)))
+}
+
+// identifies the board in the cache of the search
+func key(b core.Position) interface{} {
+	if x, ok := b.(core.Bitboard); ok {
+		return x
+	}
+
+	rows, cols := b.Rows(), b.Cols()
+	k := make([]byte, 0, rows*cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			k = append(k, rank(b.Block(i, j)))
+		}
+	}
+	return string(k)
+}
+
+func lineHeuristic(line []uint8) float64 {
+	var (
+		sum, empty, merges float64
+		prev               uint8
+		counter            int
+	)
+	for _, r := range line {
+		sum += sumPowers[r]
+		if r == 0 {
+			empty++
+			continue
+		}
+		if prev == r {
+			counter++
+		} else if counter > 0 {
+			merges += float64(1 + counter)
+			counter = 0
+		}
+		prev = r
+	}
+	if counter > 0 {
+		merges += float64(1 + counter)
+	}
+
+	var monoLeft, monoRight float64
+	for k := 1; k < len(line); k++ {
+		a := monotonicPowers[line[k-1]]
+		b := monotonicPowers[line[k]]
+		if line[k-1] > line[k] {
+			monoLeft += a - b
+		} else {
+			monoRight += b - a
+		}
+	}
+
+	return lostPenalty/float64(2*len(line)) +
+		emptyWeight*empty +
+		mergesWeight*merges -
+		monotonicWeight*math.Min(monoLeft, monoRight) -
+		sumWeight*sum
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,90 @@
+package termi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell"
+)
+
+// how the blocks are told apart
+const (
+	FillAuto    = "auto"    // patterns if the terminal has 8 colors or less
+	FillColor   = "color"   // colors of the theme
+	FillPattern = "pattern" // patterns, and the basic colors if there are any
+)
+
+// Fills lists the values accepted by SetAccessibility.
+var Fills = []string{FillAuto, FillColor, FillPattern}
+
+// CheckFill reports an error if the fill is not one of Fills.
+func CheckFill(fill string) error {
+	for _, f := range Fills {
+		if fill == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid fill %q, must be one of %s", fill, strings.Join(Fills, ", "))
+}
+
+// patterns of the blocks 2, 4, 8... growing denser up to 2048
+var patterns = []rune(".:-=+~*xo%#@░▒▓&$")
+
+// colors of the patterns on terminals with the 8 basic colors,
+// black is left for the background
+var patternColors = []tcell.Color{
+	tcell.ColorMaroon, tcell.ColorGreen, tcell.ColorOlive, tcell.ColorNavy,
+	tcell.ColorPurple, tcell.ColorTeal, tcell.ColorSilver,
+}
+
+// numbers are labeled in the reverse of the default colors of the
+// terminal, which is readable with any theme and any number of colors
+var labelStyle = tcell.StyleDefault.Reverse(true).Bold(true)
+
+// SetAccessibility chooses how the blocks are told apart (one of Fills),
+// and whether numbers are always labeled in plain text, also when they
+// are drawn with the digit font.
+func (t *TermGame) SetAccessibility(fill string, labels bool) error {
+	if err := CheckFill(fill); err != nil {
+		return err
+	}
+	t.board.patterns = fill == FillPattern || (fill == FillAuto && t.screen.Colors() <= 8)
+	t.board.labels = labels
+	return nil
+}
+
+func (b *board) drawPatternBlock(val int, x int, y int) {
+	l := b.layout
+	i := blockIndex(val)
+	st := tcell.StyleDefault
+	if b.screen.Colors() >= 8 {
+		st = st.Foreground(patternColors[i%len(patternColors)])
+	}
+	fillRect(l.blockWidth, l.blockHeight, x, y, patterns[i%len(patterns)], b.screen, st)
+	b.drawLabel(val, x+l.blockHeight/2, y, labelStyle)
+}
+
+// draws the number centered in the row of the block,
+// padded with a space on each side if it fits
+func (b *board) drawLabel(val int, x int, y int, st tcell.Style) {
+	str := shortNumber(val, b.layout.blockWidth)
+	if st == labelStyle && len(str)+2 <= b.layout.blockWidth {
+		str = " " + str + " "
+	}
+	drawString(str, x, y+(b.layout.blockWidth-len(str))/2, b.screen, st)
+}
+
+// returns the number, shortened with a metric suffix (16777k, 268M...)
+// if it is wider than width, as blocks of endless games can be
+func shortNumber(val int, width int) string {
+	str := strconv.Itoa(val)
+	for _, suffix := range "kMGTPE" {
+		if len(str) <= width {
+			break
+		}
+		val /= 1000
+		str = strconv.Itoa(val) + string(suffix)
+	}
+	return str
+}
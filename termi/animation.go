@@ -0,0 +1,122 @@
+package termi
+
+import (
+	"time"
+
+	"github.com/cicovic-andrija/2048/core"
+	"github.com/gdamore/tcell"
+)
+
+const (
+	// DefaultAnimation is the usual duration of the animation of a move.
+	DefaultAnimation = 120 * time.Millisecond
+
+	slideFrames = 4 // blocks slide to their new cells
+	popFrames   = 2 // merged blocks pop, the spawned block fades in
+)
+
+// animates the transition of the board over the duration, and ends with
+// the board drawn as it is now; without a transition it is just drawn
+func (b *board) animate(tr core.Transition, d time.Duration) {
+	if d <= 0 || len(tr.Tiles) == 0 {
+		b.redraw()
+		return
+	}
+	frame := d / (slideFrames + popFrames)
+
+	for f := 1; f <= slideFrames; f++ {
+		b.drawBackground()
+		// blocks that stay in place first, the moving ones slide over them
+		for _, moving := range []bool{false, true} {
+			for _, t := range tr.Tiles {
+				if t.Moved() != moving {
+					continue
+				}
+				fx, fy := b.cellPos(t.From)
+				tx, ty := b.cellPos(t.To)
+				b.drawBlock(t.Value, fx+(tx-fx)*f/slideFrames, fy+(ty-fy)*f/slideFrames)
+			}
+		}
+		b.showFrame(frame)
+	}
+
+	merged := make(map[core.Cell]bool)
+	for _, t := range tr.Tiles {
+		if t.Merged {
+			merged[t.To] = true
+		}
+	}
+	for f := 1; f <= popFrames; f++ {
+		b.drawBackground()
+		for i := 0; i < b.game.Rows; i++ {
+			for j := 0; j < b.game.Cols; j++ {
+				c := core.Cell{Row: i, Col: j}
+				val := b.game.Block(i, j)
+				x, y := b.cellPos(c)
+				switch {
+				case val == 0:
+				case tr.Spawn.Value != 0 && c == tr.Spawn.Cell:
+					b.drawSpawn(val, x, y, float64(f)/(popFrames+1))
+				case merged[c] && f == 1:
+					b.drawPop(val, x, y)
+				default:
+					b.drawBlock(val, x, y)
+				}
+			}
+		}
+		b.showFrame(frame)
+	}
+
+	b.redraw()
+}
+
+func (b *board) showFrame(d time.Duration) {
+	b.screen.Show()
+	time.Sleep(d)
+}
+
+// draws the block one cell larger on every side, into the gaps
+func (b *board) drawPop(val int, x int, y int) {
+	l := b.layout
+	dx, dy := min(1, l.vgap), min(1, l.hgap)
+	st := tcell.StyleDefault.Background(b.theme.blockColors(val).bg)
+	if b.patterns {
+		st = labelStyle
+	}
+	drawRect(l.blockWidth+2*dy, l.blockHeight+2*dx, x-dx, y-dy, b.screen, st)
+	b.drawBlock(val, x, y)
+}
+
+// draws the spawned block partly faded in, from the color of an empty
+// cell (frac 0) to the color of the block (frac 1)
+func (b *board) drawSpawn(val int, x int, y int, frac float64) {
+	if b.patterns {
+		if frac >= 0.5 {
+			b.drawBlock(val, x, y)
+		}
+		return
+	}
+	l := b.layout
+	bg := blend(b.theme.empty, b.theme.blockColors(val).bg, frac)
+	drawRect(l.blockWidth, l.blockHeight, x, y, b.screen, tcell.StyleDefault.Background(bg))
+}
+
+// mixes the colors, colors without a known RGB value aren't mixed
+func blend(from tcell.Color, to tcell.Color, frac float64) tcell.Color {
+	r1, g1, b1 := from.RGB()
+	r2, g2, b2 := to.RGB()
+	if r1 < 0 || r2 < 0 {
+		return to
+	}
+	mix := func(a int32, b int32) int32 {
+		return a + int32(float64(b-a)*frac)
+	}
+	return tcell.NewRGBColor(mix(r1, r2), mix(g1, g2), mix(b1, b2))
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
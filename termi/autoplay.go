@@ -0,0 +1,147 @@
+package termi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cicovic-andrija/2048/core"
+	"github.com/gdamore/tcell"
+)
+
+func (t *TermGame) updateAutoplayHeader(paused bool, over bool) {
+	switch {
+	case over:
+		t.header.text = fmt.Sprintf(
+			"AUTOPLAY OVER! Score: %d / Highest block: %d / Moves: %d\nPress Esc to exit",
+			t.game.Score(), t.game.HighestBlock(), t.game.Moves(),
+		)
+		t.header.style = t.theme.success
+	case paused:
+		t.header.text = fmt.Sprintf(
+			"AUTOPLAY PAUSED (Space to resume / Esc to quit)\nScore: %d / Highest block: %d / Moves: %d",
+			t.game.Score(), t.game.HighestBlock(), t.game.Moves(),
+		)
+		t.header.style = t.theme.failure
+	default:
+		t.header.text = fmt.Sprintf(
+			"AUTOPLAY (Space to pause / Esc to quit)\nScore: %d / Highest block: %d / Moves: %d",
+			t.game.Score(), t.game.HighestBlock(), t.game.Moves(),
+		)
+		t.header.style = t.theme.info
+	}
+
+	t.redrawHeader()
+}
+
+// autoplayer lets a bot play one move every delay,
+// while the keyboard can pause the game or quit
+type autoplayer struct {
+	t      *TermGame
+	next   core.MoveFunc
+	delay  time.Duration
+	events <-chan tcell.Event
+	paused bool
+	quit   bool
+}
+
+func (p *autoplayer) Act(v core.View) core.Action {
+	if v.Outcome() == core.GameOverWin {
+		return core.Action{Kind: core.KeepGoingAction}
+	}
+
+	timer := time.NewTimer(p.delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case ev, open := <-p.events:
+			if !open {
+				return core.Action{Kind: core.QuitAction}
+			}
+			switch ev := ev.(type) {
+			case *tcell.EventResize:
+				p.t.redrawComponents()
+			case *tcell.EventKey:
+				if ev.Key() == tcell.KeyEscape {
+					p.quit = true
+					return core.Action{Kind: core.QuitAction}
+				}
+				if ev.Key() == tcell.KeyRune && ev.Rune() == ' ' {
+					p.paused = !p.paused
+					if !p.paused {
+						timer.Reset(p.delay)
+					}
+					p.t.updateAutoplayHeader(p.paused, false)
+					p.t.screen.Show()
+				}
+			}
+
+		case <-timer.C:
+			if p.paused {
+				continue
+			}
+			dir, ok := p.next(v)
+			if !ok {
+				return core.Action{Kind: core.QuitAction}
+			}
+			return core.PushTo(dir)
+		}
+	}
+}
+
+// Autoplay lets next play the game, one move every delay. The game is
+// continued after it is won, and lasts until no moves remain.
+func (t *TermGame) Autoplay(next core.MoveFunc, delay time.Duration) error {
+	if t.game.Phase == core.Finished {
+		return fmt.Errorf("terminal game has already finished")
+	}
+
+	// events are polled in the background, so that the screen
+	// stays responsive between the moves
+	events := make(chan tcell.Event)
+	go func() {
+		for {
+			ev := t.screen.PollEvent()
+			if ev == nil { // screen finalized
+				close(events)
+				return
+			}
+			events <- ev
+		}
+	}()
+
+	p := &autoplayer{
+		t:      t,
+		next:   next,
+		delay:  delay,
+		events: events,
+	}
+
+	t.updateAutoplayHeader(false, false)
+	t.redrawComponents()
+
+	core.Play(t.game, p, func(a core.Action, ok bool) {
+		t.redrawMove(a, ok)
+		t.updateAutoplayHeader(p.paused, false)
+		t.screen.Show()
+	})
+	if p.quit {
+		t.screen.Fini()
+		return nil
+	}
+
+	t.updateAutoplayHeader(false, true)
+	t.screen.Show()
+	for ev := range events {
+		switch ev := ev.(type) {
+		case *tcell.EventResize:
+			t.redrawComponents()
+		case *tcell.EventKey:
+			if ev.Key() == tcell.KeyEscape {
+				t.screen.Fini()
+				return nil
+			}
+		}
+	}
+	return nil
+}
@@ -1,89 +1,24 @@
 package termi
 
-import "github.com/gdamore/tcell"
+import (
+	"github.com/gdamore/tcell"
+)
 
 type blockProps struct {
-	inBlockPad int
-	bg         tcell.Style
-	fg         tcell.Style
+	bg tcell.Style
+	fg tcell.Style // background in the color of the digit font
 }
 
-var blkPropMap = map[int]blockProps{
-	2: blockProps{
-		inBlockPad: 6,
-		bg:         tcell.StyleDefault.Background(colorEggShellWhite),
-		fg:         tcell.StyleDefault.Background(colorDarkGray),
-	},
-	4: blockProps{
-		inBlockPad: 6,
-		bg:         tcell.StyleDefault.Background(tcell.Color230),
-		fg:         tcell.StyleDefault.Background(colorDarkGray),
-	},
-
-	8: blockProps{
-		inBlockPad: 6,
-		bg:         tcell.StyleDefault.Background(tcell.Color222),
-		fg:         tcell.StyleDefault.Background(tcell.ColorWhite),
-	},
-
-	16: blockProps{
-		inBlockPad: 3,
-		bg:         tcell.StyleDefault.Background(tcell.Color215),
-		fg:         tcell.StyleDefault.Background(tcell.ColorWhite),
-	},
-
-	32: blockProps{
-		inBlockPad: 3,
-		bg:         tcell.StyleDefault.Background(tcell.Color209),
-		fg:         tcell.StyleDefault.Background(tcell.ColorWhite),
-	},
-
-	64: blockProps{
-
-		inBlockPad: 3,
-		bg:         tcell.StyleDefault.Background(tcell.Color196),
-		fg:         tcell.StyleDefault.Background(tcell.ColorWhite),
-	},
-
-	128: blockProps{
-		inBlockPad: 1,
-		bg:         tcell.StyleDefault.Background(tcell.Color228),
-		fg:         tcell.StyleDefault.Background(tcell.ColorWhite),
-	},
-
-	256: blockProps{
-		inBlockPad: 1,
-		bg:         tcell.StyleDefault.Background(tcell.Color227),
-		fg:         tcell.StyleDefault.Background(tcell.ColorWhite),
-	},
-
-	512: blockProps{
-		inBlockPad: 1,
-		bg:         tcell.StyleDefault.Background(tcell.Color226),
-		fg:         tcell.StyleDefault.Background(tcell.ColorWhite),
-	},
-
-	1024: blockProps{
-		inBlockPad: 0,
-		bg:         tcell.StyleDefault.Background(tcell.Color221),
-		fg:         tcell.StyleDefault.Background(tcell.ColorWhite),
-	},
-
-	2048: blockProps{
-		inBlockPad: 0,
-		bg:         tcell.StyleDefault.Background(tcell.Color220),
-		fg:         tcell.StyleDefault.Background(tcell.ColorWhite),
-	},
-
-	4096: blockProps{
-		inBlockPad: 0,
-		bg:         tcell.StyleDefault.Background(tcell.Color212),
-		fg:         tcell.StyleDefault.Background(tcell.ColorWhite),
-	},
+func (th *theme) blockProps(val int) blockProps {
+	c := th.blockColors(val)
+	return blockProps{
+		bg: tcell.StyleDefault.Background(c.bg),
+		fg: tcell.StyleDefault.Background(c.fg),
+	}
+}
 
-	8192: blockProps{
-		inBlockPad: 0,
-		bg:         tcell.StyleDefault.Background(tcell.Color211),
-		fg:         tcell.StyleDefault.Background(tcell.ColorWhite),
-	},
+// style for numbers drawn as plain text, in the color of the digit font
+func (p blockProps) textStyle() tcell.Style {
+	_, color, _ := p.fg.Decompose()
+	return p.bg.Foreground(color).Bold(true)
 }
@@ -20,18 +20,19 @@ func testDrawNumbers(s tcell.Screen) {
 
 func testDrawBlocks(s tcell.Screen) {
 	s.Clear()
+	l := layouts[0]
 	ax, ay := 0, 0
 	for n := 2; n <= 8192; n *= 2 {
-		bg, fg := blkPropMap[n].bg, blkPropMap[n].fg
-		for x := 0; x < blockHeight; x++ {
-			for y := 0; y < blockWidth; y++ {
-				s.SetContent(ay+y, ax+x, ' ', nil, bg)
+		props := classicTheme.blockProps(n)
+		for x := 0; x < l.blockHeight; x++ {
+			for y := 0; y < l.blockWidth; y++ {
+				s.SetContent(ay+y, ax+x, ' ', nil, props.bg)
 			}
 		}
-		drawNumber(n, ax, ay+blkPropMap[n].inBlockPad, s, fg)
-		ay += blockWidth
+		l.font.drawNumber(n, ax+1, ay+(l.blockWidth-l.font.width(n))/2, s, props)
+		ay += l.blockWidth
 		if n == 128 {
-			ax += blockHeight
+			ax += l.blockHeight
 			ay = 0
 		}
 	}
@@ -39,14 +40,14 @@ func testDrawBlocks(s tcell.Screen) {
 }
 
 func testDrawBoxedNumber(s tcell.Screen) {
-	fg := tcell.StyleDefault
-	bg := tcell.StyleDefault.Reverse(true)
+	l := layouts[0]
+	props := blockProps{bg: tcell.StyleDefault.Reverse(true), fg: tcell.StyleDefault}
 	s.Clear()
-	for x := 0; x < blockHeight; x++ {
-		for y := 0; y < blockWidth; y++ {
-			s.SetContent(y, x, ' ', nil, bg)
+	for x := 0; x < l.blockHeight; x++ {
+		for y := 0; y < l.blockWidth; y++ {
+			s.SetContent(y, x, ' ', nil, props.bg)
 		}
 	}
-	drawNumber(2048, 0, 0, s, fg)
+	l.font.drawNumber(2048, 1, 1, s, props)
 	s.Show()
 }
@@ -0,0 +1,94 @@
+package termi
+
+import (
+	"strconv"
+
+	"github.com/gdamore/tcell"
+)
+
+// font draws numbers with the digits of bitmap, a cell of the screen
+// holds cols x rows pixels of a digit
+type font struct {
+	cols int
+	rows int
+
+	// rune of a cell whose pixels are set in mask (bit r*cols+c is the
+	// pixel in row r and column c of the cell), nil if cells hold one
+	// pixel, they are then drawn in the color of the digits
+	runes func(mask int) rune
+}
+
+var (
+	largeFont  = &font{cols: 1, rows: 1}
+	mediumFont = &font{cols: 1, rows: 2, runes: halfBlock}
+	smallFont  = &font{cols: 2, rows: 4, runes: braille}
+)
+
+func halfBlock(mask int) rune {
+	return []rune(" ▀▄█")[mask]
+}
+
+// the dots of a braille pattern are numbered down the left column and
+// then down the right one, with the dots of the bottom row last
+func braille(mask int) rune {
+	dots := [8]uint{0, 3, 1, 4, 2, 5, 6, 7}
+	r := rune(0x2800)
+	for i, dot := range dots {
+		if mask&(1<<uint(i)) != 0 {
+			r |= 1 << dot
+		}
+	}
+	return r
+}
+
+// size of a digit, in cells
+func (f *font) digitWidth() int {
+	return (glyphWidth + f.cols - 1) / f.cols
+}
+
+func (f *font) digitHeight() int {
+	return (glyphHeight + f.rows - 1) / f.rows
+}
+
+// width of the number, with a column between the digits
+func (f *font) width(n int) int {
+	return len(strconv.Itoa(n))*(f.digitWidth()+1) - 1
+}
+
+// draws the number with its top-left corner at the coordinates,
+// in the colors of the block
+func (f *font) drawNumber(n int, tlx int, tly int, s tcell.Screen, p blockProps) {
+	for _, c := range strconv.Itoa(n) {
+		f.drawDigit(int(c-'0'), tlx, tly, s, p)
+		tly += f.digitWidth() + 1
+	}
+}
+
+func (f *font) drawDigit(digit int, tlx int, tly int, s tcell.Screen, p blockProps) {
+	for x := 0; x < f.digitHeight(); x++ {
+		for y := 0; y < f.digitWidth(); y++ {
+			mask := f.mask(digit, x, y)
+			switch {
+			case mask == 0:
+			case f.runes == nil:
+				s.SetContent(tly+y, tlx+x, ' ', nil, p.fg)
+			default:
+				s.SetContent(tly+y, tlx+x, f.runes(mask), nil, p.textStyle())
+			}
+		}
+	}
+}
+
+// pixels of the digit in the cell at row x and column y of the digit
+func (f *font) mask(digit int, x int, y int) int {
+	mask := 0
+	for r := 0; r < f.rows; r++ {
+		for c := 0; c < f.cols; c++ {
+			row, col := x*f.rows+r, y*f.cols+c
+			if row < glyphHeight && col < glyphWidth && bitmap[digit]&(1<<uint(glyphWidth*row+col)) != 0 {
+				mask |= 1 << uint(r*f.cols+c)
+			}
+		}
+	}
+	return mask
+}
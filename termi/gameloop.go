@@ -1,19 +1,75 @@
 package termi
 
 import (
+	"time"
+
 	"github.com/cicovic-andrija/2048/core"
+	"github.com/cicovic-andrija/2048/keys"
 )
 
-func NewTerminalGraphicsGame(player string, size int, target int, undos int) error {
-	game, err := core.NewGame(player, size, target, undos)
-	if err != nil {
+// Options configure the terminal interface, the zero value stands
+// for the default keys, theme and fill, without animations.
+type Options struct {
+	Keys  keys.Bindings // nil for the default bindings
+	Theme string        // empty for DefaultTheme
+
+	Fill   string // one of Fills, empty for FillAuto
+	Labels bool   // always label the numbers in plain text
+
+	Animation time.Duration // of a move, usually DefaultAnimation
+}
+
+// checks the options before the screen takes over the terminal
+func (o *Options) check() error {
+	if o.Keys == nil {
+		o.Keys = keys.DefaultKeymap().Term
+	}
+	if o.Theme == "" {
+		o.Theme = DefaultTheme
+	}
+	if o.Fill == "" {
+		o.Fill = FillAuto
+	}
+	if err := CheckKeys(o.Keys); err != nil {
 		return err
 	}
+	if err := CheckFill(o.Fill); err != nil {
+		return err
+	}
+	return CheckTheme(o.Theme)
+}
+
+// creates the game in the top-left corner of the screen
+func newTermGame(game *core.Game, opts Options) (*TermGame, error) {
+	if err := opts.check(); err != nil {
+		return nil, err
+	}
 
 	termGame, err := NewTermGame(game, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	termGame.SetKeys(opts.Keys)
+	termGame.SetTheme(opts.Theme)
+	termGame.SetAccessibility(opts.Fill, opts.Labels)
+	termGame.SetAnimation(opts.Animation)
+	return termGame, nil
+}
+
+func NewTerminalGraphicsGame(game *core.Game, opts Options) error {
+	termGame, err := newTermGame(game, opts)
 	if err != nil {
 		return err
 	}
 
 	return termGame.Run()
 }
+
+func NewAutoplayGame(game *core.Game, next core.MoveFunc, delay time.Duration, opts Options) error {
+	termGame, err := newTermGame(game, opts)
+	if err != nil {
+		return err
+	}
+
+	return termGame.Autoplay(next, delay)
+}
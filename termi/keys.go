@@ -0,0 +1,78 @@
+package termi
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cicovic-andrija/2048/keys"
+	"github.com/gdamore/tcell"
+)
+
+// Enter keeps going after a win
+var reservedKeys = []string{"Enter"}
+
+// normalized names of the special keys
+var keyNames = make(map[string]bool)
+
+func init() {
+	for _, name := range tcell.KeyNames {
+		keyNames[keys.Normalize(name)] = true
+	}
+}
+
+func validKey(key string) bool {
+	if r, n := utf8.DecodeRuneInString(key); n == len(key) {
+		return unicode.IsPrint(r)
+	}
+	return keyNames[keys.Normalize(key)]
+}
+
+// CheckKeys validates the key bindings of the terminal interface.
+func CheckKeys(b keys.Bindings) error {
+	if err := b.Check(keys.Actions, validKey, reservedKeys...); err != nil {
+		return fmt.Errorf("terminal key bindings: %v", err)
+	}
+	return nil
+}
+
+// returns the normalized name of the key of the event
+func keyName(ev *tcell.EventKey) string {
+	if ev.Key() == tcell.KeyRune {
+		return keys.Normalize(string(ev.Rune()))
+	}
+	return keys.Normalize(tcell.KeyNames[ev.Key()])
+}
+
+// SetKeys replaces the key bindings of the game.
+func (t *TermGame) SetKeys(b keys.Bindings) error {
+	if err := CheckKeys(b); err != nil {
+		return err
+	}
+	t.keys = b.Clone()
+	t.index = t.keys.Index()
+	t.header.text = t.welcome()
+	return nil
+}
+
+// the help overlay lists the key bindings on top of the board
+func (t *TermGame) helpLines() []string {
+	lines := []string{"KEY BINDINGS", ""}
+	for _, a := range keys.Actions {
+		lines = append(lines, fmt.Sprintf("%-12s %s", t.keys.Label(a), a.Description()))
+	}
+	return append(lines,
+		fmt.Sprintf("%-12s %s", "Enter", "keep going after a win"),
+		fmt.Sprintf("%-12s %s", "Mouse", "drag the board to push, click a button"),
+		"",
+		"Press any key to close",
+	)
+}
+
+func (t *TermGame) redrawHelp() {
+	if !t.help || t.tooSmall {
+		return
+	}
+
+	t.drawOverlay(t.helpLines(), t.theme.notice)
+}
@@ -0,0 +1,102 @@
+package termi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell"
+)
+
+// fitScreen lays the components out in the middle of the screen, with the
+// largest board that fits, it reports whether the header, the toolbar and
+// the smallest board fit at all
+func (t *TermGame) fitScreen() bool {
+	cols, rows := t.screen.Size()
+	cols, rows = cols-t.left, rows-t.top
+
+	b := t.board
+	text := t.textWidth()
+	fits := b.fit(cols, rows-headerHeight-toolbarHeight) && text <= cols
+
+	t.refx = t.top + max(0, rows-headerHeight-toolbarHeight-b.height)/2
+	t.refy = t.left + max(0, cols-max(text, b.width+b.margin))/2
+	b.refx = t.refx + headerHeight + toolbarHeight
+	b.refy = t.refy
+	t.stats.fitBoard(b)
+	t.header.width = b.width
+	return fits
+}
+
+// columns taken by the widest line of the header, or by the toolbar
+func (t *TermGame) textWidth() int {
+	width := 0
+	for _, str := range strings.Split(t.header.text, "\n") {
+		width = max(width, len([]rune(str)))
+	}
+	if len(t.toolbar) > 0 {
+		toolbar := 2 * (len(t.toolbar) - 1) // between the buttons
+		for i := range t.toolbar {
+			toolbar += len(t.toolbar[i].text())
+		}
+		width = max(width, toolbar)
+	}
+	return width
+}
+
+// reports whether the header, as it is now, fits where it is drawn
+func (t *TermGame) headerFits() bool {
+	cols, _ := t.screen.Size()
+	return t.refy+t.textWidth() <= cols
+}
+
+// the size of the screen needed by the smallest layout
+func (t *TermGame) minScreenSize() (cols int, rows int) {
+	b := *t.board
+	b.setLayout(layouts[len(layouts)-1])
+	return t.left + max(t.textWidth(), b.width+b.margin), t.top + headerHeight + toolbarHeight + b.height
+}
+
+// draws the lines in a box on top of the board, centered on it if it fits
+func (t *TermGame) drawOverlay(lines []string, st tcell.Style) {
+	width := 0
+	for _, str := range lines {
+		width = max(width, len([]rune(str)))
+	}
+	width += 4 // padding
+	height := len(lines) + 2
+
+	x, y := t.board.refx, t.board.refy
+	if d := t.board.height - height; d > 0 {
+		x += d / 2
+	}
+	if d := t.board.width - width; d > 0 {
+		y += d / 2
+	}
+
+	drawRect(width, height, x, y, t.screen, st)
+	for i, str := range lines {
+		drawString(str, x+1+i, y+2, t.screen, st)
+	}
+}
+
+// asks for a larger terminal, in the middle of the screen
+func (t *TermGame) drawTooSmall() {
+	cols, rows := t.screen.Size()
+	needCols, needRows := t.minScreenSize()
+	lines := []string{
+		"TERMINAL TOO SMALL",
+		fmt.Sprintf("%dx%d, needs %dx%d", cols, rows, needCols, needRows),
+	}
+	for i, str := range lines {
+		x := max(0, rows-len(lines))/2 + i
+		y := max(0, cols-len(str)) / 2
+		drawString(str, x, y, t.screen, t.theme.failure)
+	}
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
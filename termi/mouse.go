@@ -0,0 +1,124 @@
+package termi
+
+import (
+	"github.com/cicovic-andrija/2048/core"
+	"github.com/gdamore/tcell"
+)
+
+// minimum length of a swipe, in columns; rows count double since
+// terminal cells are about twice as tall as they are wide
+const minSwipeLength = 4
+
+// button is a clickable label of the toolbar below the header
+type button struct {
+	label  string
+	action core.ActionKind
+	col    int // first column, set when the toolbar is drawn
+}
+
+func (b *button) text() string {
+	return "[ " + b.label + " ]"
+}
+
+func newToolbar() []button {
+	return []button{
+		{label: "Undo", action: core.UndoAction},
+		{label: "New Game", action: core.NewGameAction},
+		{label: "Quit", action: core.QuitAction},
+	}
+}
+
+func (t *TermGame) redrawToolbar() {
+	if t.tooSmall {
+		return
+	}
+	col := t.refy
+	for i := range t.toolbar {
+		b := &t.toolbar[i]
+		b.col = col
+		drawString(b.text(), t.refx+headerHeight, col, t.screen, t.theme.button)
+		col += len(b.text()) + 2
+	}
+}
+
+// returns the button at the screen position, nil if there is none
+func (t *TermGame) buttonAt(x int, y int) *button {
+	if y != t.refx+headerHeight {
+		return nil
+	}
+	for i := range t.toolbar {
+		b := &t.toolbar[i]
+		if x >= b.col && x < b.col+len(b.text()) {
+			return b
+		}
+	}
+	return nil
+}
+
+// reports whether the screen position is on the board
+func (b *board) contains(x int, y int) bool {
+	return y >= b.refx && y < b.refx+b.height && x >= b.refy && x < b.refy+b.width
+}
+
+// translates the vector of a drag into a direction,
+// ok is false if the drag is too short to be a swipe
+func swipeDirection(dx int, dy int) (dir core.Direction, ok bool) {
+	dy *= 2
+	if abs(dx) < minSwipeLength && abs(dy) < minSwipeLength {
+		return dir, false
+	}
+	if abs(dx) > abs(dy) {
+		if dx > 0 {
+			return core.Right, true
+		}
+		return core.Left, true
+	}
+	if dy > 0 {
+		return core.Down, true
+	}
+	return core.Up, true
+}
+
+// mouse tracks the left button, a press and release on the same toolbar
+// button is a click, and a drag that starts on the board is a swipe
+type mouse struct {
+	pressed bool
+	x       int // position of the press
+	y       int
+}
+
+// returns the action completed by the mouse event, if any
+func (m *mouse) handle(t *TermGame, ev *tcell.EventMouse) (a core.Action, ok bool) {
+	x, y := ev.Position()
+	if ev.Buttons()&tcell.Button1 != 0 {
+		if !m.pressed {
+			m.pressed, m.x, m.y = true, x, y
+		}
+		return a, false
+	}
+	if !m.pressed {
+		return a, false
+	}
+	m.pressed = false
+
+	if b := t.buttonAt(m.x, m.y); b != nil {
+		if t.buttonAt(x, y) != b {
+			return a, false
+		}
+		return core.Action{Kind: b.action}, true
+	}
+
+	if t.board.contains(m.x, m.y) {
+		if dir, ok := swipeDirection(x-m.x, y-m.y); ok {
+			return core.PushTo(dir), true
+		}
+	}
+	return a, false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,106 @@
+package termi
+
+import (
+	"fmt"
+
+	"github.com/cicovic-andrija/2048/core"
+	"github.com/gdamore/tcell"
+)
+
+// PlayReplay plays back a recorded game, one move per key press.
+func PlayReplay(r *core.Replay, opts Options) error {
+	replayer, err := core.NewReplayer(r)
+	if err != nil {
+		return err
+	}
+
+	termGame, err := newTermGame(replayer.Game(), opts)
+	if err != nil {
+		return err
+	}
+
+	termGame.runReplay(replayer)
+	return nil
+}
+
+func (t *TermGame) updateReplayHeader(p *core.Replayer, outcome core.Outcome, err error) {
+	switch {
+	case err != nil:
+		t.header.text = fmt.Sprintf("%v\nPress Esc to exit", err)
+		t.header.style = t.theme.failure
+	case p.Done():
+		t.header.text = fmt.Sprintf(
+			"END OF REPLAY (%v) Score: %d\nPress Esc to exit",
+			outcome, t.game.Score(),
+		)
+		t.header.style = t.theme.success
+	default:
+		history := t.game.History()
+		t.header.text = fmt.Sprintf(
+			"REPLAY: %s / Move %d of %d (%v)\nScore: %d / Seed %d",
+			t.game.Player, p.Pos(), p.Len(), history[len(history)-1].Dir,
+			t.game.Score(), t.game.Seed(),
+		)
+		t.header.style = t.theme.info
+	}
+
+	t.redrawHeader()
+}
+
+// replayStepper plays back the replay one move per key press
+type replayStepper struct {
+	t        *TermGame
+	replayer *core.Replayer
+	quit     bool
+}
+
+func (p *replayStepper) Act(v core.View) core.Action {
+	if v.Outcome() == core.GameOverWin {
+		return p.replayer.Act(v)
+	}
+
+	for {
+		switch ev := p.t.screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			p.t.redrawComponents()
+
+		case *tcell.EventKey:
+			if ev.Key() == tcell.KeyEscape {
+				p.quit = true
+				return core.Action{Kind: core.QuitAction}
+			}
+			if ev.Key() == tcell.KeyRight || (ev.Key() == tcell.KeyRune && ev.Rune() == ' ') {
+				return p.replayer.Act(v)
+			}
+		}
+	}
+}
+
+func (t *TermGame) runReplay(p *core.Replayer) {
+	t.header.text = fmt.Sprintf(
+		"REPLAY: %s / %d moves\nPress Space or Right arrow to step / Esc to quit",
+		t.game.Player, p.Len(),
+	)
+	t.redrawComponents()
+
+	stepper := &replayStepper{t: t, replayer: p}
+	outcome := core.Play(t.game, stepper, func(a core.Action, ok bool) {
+		if a.Kind != core.PushAction {
+			return
+		}
+		t.redrawMove(a, ok)
+		t.updateReplayHeader(p, t.game.Outcome(), nil)
+		t.screen.Show()
+	})
+	if stepper.quit {
+		t.screen.Fini()
+		return
+	}
+
+	if err := p.Err(); err != nil {
+		t.updateReplayHeader(p, outcome, err)
+		t.screen.Show()
+	}
+	t.waitKey(tcell.KeyEscape)
+	t.screen.Fini()
+}
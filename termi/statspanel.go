@@ -0,0 +1,87 @@
+package termi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cicovic-andrija/2048/core"
+	"github.com/gdamore/tcell"
+)
+
+const (
+	statsPanelWidth = 34
+	statsPanelGap   = 2 // columns between the board and the panel
+)
+
+// statsPanel shows the statistics of the game next to the board
+type statsPanel struct {
+	game    *core.Game
+	visible bool
+
+	// absolute coordinates of the top-left corner
+	refx int
+	refy int
+
+	screen tcell.Screen
+	theme  *theme
+}
+
+func newStatsPanel(game *core.Game, screen tcell.Screen) *statsPanel {
+	return &statsPanel{
+		game:   game,
+		screen: screen,
+		theme:  classicTheme,
+	}
+}
+
+// places the panel to the right of the board
+func (p *statsPanel) fitBoard(b *board) {
+	p.refx = b.refx
+	p.refy = b.refy + b.width + statsPanelGap
+}
+
+func (p *statsPanel) lines() []string {
+	s := p.game.Stats()
+	round := func(d time.Duration) time.Duration {
+		return d.Round(100 * time.Millisecond)
+	}
+
+	lines := []string{
+		"STATISTICS",
+		"",
+		fmt.Sprintf("Moves: %d", s.TotalMoves()),
+		fmt.Sprintf("  Up   %5d   Left  %5d", s.Moves[core.Up], s.Moves[core.Left]),
+		fmt.Sprintf("  Down %5d   Right %5d", s.Moves[core.Down], s.Moves[core.Right]),
+		fmt.Sprintf("Wasted pushes: %d", s.WastedPushes),
+		fmt.Sprintf("Undos used: %d", s.Undos),
+		fmt.Sprintf("Time per move: %v", round(s.AverageMoveTime())),
+		fmt.Sprintf("  fastest %v, slowest %v", round(s.FastestMove), round(s.SlowestMove)),
+		"",
+		fmt.Sprintf("%6s %7s %9s", "Block", "Merges", "Reached"),
+	}
+
+	blocks := make([]int, 0, len(s.Merges))
+	for block := range s.Merges {
+		blocks = append(blocks, block)
+	}
+	sort.Ints(blocks)
+	for _, block := range blocks {
+		lines = append(lines, fmt.Sprintf(
+			"%6d %7d %9v", block, s.Merges[block], s.Reached[block].Round(time.Second),
+		))
+	}
+	return lines
+}
+
+func (p *statsPanel) redraw() {
+	if !p.visible {
+		return
+	}
+
+	lines := p.lines()
+	drawRect(statsPanelWidth, len(lines)+2, p.refx, p.refy, p.screen, p.theme.info)
+	for i, str := range lines {
+		drawString(str, p.refx+1+i, p.refy+1, p.screen, p.theme.info)
+	}
+}
@@ -1,69 +1,160 @@
 package termi
 
 import (
+	"strconv"
+
 	"github.com/cicovic-andrija/2048/core"
 	"github.com/gdamore/tcell"
 )
 
 const (
-	digitFieldWidth    = 5
-	digitFieldHeight   = 7
-	blockWidth         = core.MaxBlockDigits*(digitFieldWidth-1) + 1
-	blockHeight        = digitFieldHeight
+	fontDigits         = 4 // largest number of digits drawn with a font
 	horizontalBlockGap = 2
 	verticalBlockGap   = 1
 )
 
+// layout describes how blocks are drawn
+type layout struct {
+	blockWidth  int
+	blockHeight int
+	hgap        int   // horizontal gap between blocks
+	vgap        int   // vertical gap between blocks
+	font        *font // of the numbers, nil if they are drawn as plain text
+}
+
+// a layout with blocks that fit the numbers of the font,
+// with a margin of a cell on every side
+func fontLayout(f *font) layout {
+	return layout{
+		blockWidth:  fontDigits*(f.digitWidth()+1) + 1,
+		blockHeight: f.digitHeight() + 2,
+		hgap:        horizontalBlockGap,
+		vgap:        verticalBlockGap,
+		font:        f,
+	}
+}
+
+// layouts in order of preference, the first one that fits the screen is used,
+// numbers wider than the blocks of plain text are shortened (see shortNumber)
+var layouts = []layout{
+	fontLayout(largeFont),
+	fontLayout(mediumFont),
+	fontLayout(smallFont),
+	{core.MaxBlockDigits + 1, 3, 1, 1, nil},
+	{core.MaxBlockDigits + 1, 1, 1, 0, nil},
+}
+
 type board struct {
 	game *core.Game
 
+	layout layout
 	width  int
 	height int
+	margin int // columns to keep free to the right of the board
+
+	patterns bool // tell the blocks apart by patterns instead of colors
+	labels   bool // label the numbers drawn with the digit font
 
-	// absolute coordinates of some top-left reference point
+	// absolute coordinates of the top-left corner, set when
+	// the game is laid out on the screen
 	refx int
 	refy int
 
 	screen tcell.Screen
-	bg     tcell.Style
+	theme  *theme
 }
 
-func newBoard(game *core.Game, tlx int, tly int, screen tcell.Screen) *board {
-	return &board{
+func newBoard(game *core.Game, screen tcell.Screen) *board {
+	b := &board{
 		game:   game,
-		width:  game.Size*(blockWidth+horizontalBlockGap) + horizontalBlockGap,
-		height: game.Size*(blockHeight+verticalBlockGap) + verticalBlockGap,
-		refx:   tlx,
-		refy:   tly,
 		screen: screen,
-		bg:     tcell.StyleDefault.Background(colorGray),
+		theme:  classicTheme,
+	}
+	b.setLayout(layouts[0])
+	return b
+}
+
+func (b *board) setLayout(l layout) {
+	b.layout = l
+	b.width = b.game.Cols*(l.blockWidth+l.hgap) + l.hgap
+	b.height = b.game.Rows*(l.blockHeight+l.vgap) + l.vgap
+}
+
+// picks the largest layout that fits the space, along with the margin,
+// reports false (leaving the smallest layout set) if none does
+func (b *board) fit(cols int, rows int) bool {
+	for _, l := range layouts {
+		b.setLayout(l)
+		if b.width+b.margin <= cols && b.height <= rows {
+			return true
+		}
 	}
+	return false
 }
 
 func (b *board) redraw() {
-	drawRect(b.width, b.height, b.refx, b.refy, b.screen, b.bg)
-	for i := 0; i < b.game.Size; i++ {
-		for j := 0; j < b.game.Size; j++ {
-			x := b.refx + verticalBlockGap + i*(blockHeight+verticalBlockGap)
-			y := b.refy + horizontalBlockGap + j*(blockWidth+horizontalBlockGap)
+	b.drawBackground()
+	for i := 0; i < b.game.Rows; i++ {
+		for j := 0; j < b.game.Cols; j++ {
 			if val := b.game.Block(i, j); val != 0 {
-				drawRect(blockWidth, blockHeight, x, y, b.screen, blkPropMap[val].bg)
-				drawNumber(val, x, y+blkPropMap[val].inBlockPad, b.screen, blkPropMap[val].fg)
-			} else { // empty block
-				drawRect(blockWidth, blockHeight, x, y, b.screen, emptyCellStyle)
+				x, y := b.cellPos(core.Cell{Row: i, Col: j})
+				b.drawBlock(val, x, y)
 			}
 		}
 	}
 }
 
-func (b *board) push(dir core.Direction) core.Outcome {
-	outcome := b.game.Push(dir)
-	b.redraw()
-	return outcome
+// absolute coordinates of the top-left corner of the cell
+func (b *board) cellPos(c core.Cell) (x int, y int) {
+	l := b.layout
+	return b.refx + l.vgap + c.Row*(l.blockHeight+l.vgap),
+		b.refy + l.hgap + c.Col*(l.blockWidth+l.hgap)
+}
+
+// draws the board with all the cells empty
+func (b *board) drawBackground() {
+	l := b.layout
+	gapStyle := tcell.StyleDefault.Background(b.theme.board)
+	emptyStyle := tcell.StyleDefault.Background(b.theme.empty)
+	if b.patterns {
+		gapStyle, emptyStyle = tcell.StyleDefault.Reverse(true), tcell.StyleDefault
+	}
+
+	drawRect(b.width, b.height, b.refx, b.refy, b.screen, gapStyle)
+	for i := 0; i < b.game.Rows; i++ {
+		for j := 0; j < b.game.Cols; j++ {
+			x, y := b.cellPos(core.Cell{Row: i, Col: j})
+			drawRect(l.blockWidth, l.blockHeight, x, y, b.screen, emptyStyle)
+		}
+	}
 }
 
-func (b *board) undo() {
-	if b.game.Undo() {
-		b.redraw()
+// draws the block with its top-left corner at the coordinates
+func (b *board) drawBlock(val int, x int, y int) {
+	l := b.layout
+	if b.patterns {
+		b.drawPatternBlock(val, x, y)
+		return
+	}
+
+	props := b.theme.blockProps(val)
+	str := strconv.Itoa(val)
+	drawRect(l.blockWidth, l.blockHeight, x, y, b.screen, props.bg)
+	switch {
+	case l.font != nil && len(str) <= fontDigits:
+		l.font.drawNumber(
+			val,
+			x+(l.blockHeight-l.font.digitHeight())/2,
+			y+(l.blockWidth-l.font.width(val))/2,
+			b.screen,
+			props,
+		)
+		if b.labels {
+			b.drawLabel(val, x+l.blockHeight-1, y, labelStyle)
+		}
+	case b.labels:
+		b.drawLabel(val, x+l.blockHeight/2, y, labelStyle)
+	default:
+		b.drawLabel(val, x+l.blockHeight/2, y, props.textStyle())
 	}
 }
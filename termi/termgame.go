@@ -3,11 +3,19 @@ package termi
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/cicovic-andrija/2048/core"
+	"github.com/cicovic-andrija/2048/keys"
+	"github.com/cicovic-andrija/2048/solver"
 	"github.com/gdamore/tcell"
 )
 
+const (
+	headerHeight  = 2
+	toolbarHeight = 1
+)
+
 type header struct {
 	text  string
 	width int
@@ -15,15 +23,34 @@ type header struct {
 }
 
 type TermGame struct {
-	game *core.Game
+	game    *core.Game
+	advisor *solver.Solver
+	rank    int // rank of the game in the high scores, 0 if not ranked
+
+	keys    keys.Bindings
+	index   map[string]keys.Action // actions by normalized key
+	help    bool                   // whether the help overlay is shown
+	summary bool                   // whether the summary of the ended game is shown
+	theme   *theme
+
+	animation time.Duration // of a move, 0 if moves are not animated
 
-	header *header
-	board  *board
-	screen tcell.Screen
+	header  *header
+	board   *board
+	stats   *statsPanel
+	toolbar []button // empty unless the game is played by a human
+	screen  tcell.Screen
 
-	// absolute coordinates of some top-left reference point
+	// top-left corner of the part of the screen given to the game
+	top  int
+	left int
+
+	// absolute coordinates of the top-left corner of the header, the
+	// components are laid out in the middle of the screen
 	refx int
 	refy int
+
+	tooSmall bool // nothing fits the screen, a warning is shown instead
 }
 
 func NewTermGame(game *core.Game, tlx int, tly int) (*TermGame, error) {
@@ -38,128 +65,432 @@ func NewTermGame(game *core.Game, tlx int, tly int) (*TermGame, error) {
 	}
 
 	screen.HideCursor()
-	screen.DisableMouse()
-	screen.SetStyle(whiteOnBlackDefault)
-
-	board := newBoard(game, tlx+2, tly, screen)
+	screen.EnableMouse()
+	screen.SetStyle(classicTheme.screen)
 
 	header := &header{
-		text:  "NEW GAME\nUse arrow keys to play / Ctrl+U to undo / Esc to quit",
-		width: board.width,
-		style: whiteOnGreen,
+		style: classicTheme.success,
 	}
 
 	termGame := &TermGame{
-		game:   game,
-		header: header,
-		board:  board,
-		screen: screen,
-		refx:   tlx,
-		refy:   tly,
+		game:    game,
+		advisor: solver.New(solver.DefaultDepth),
+		keys:    keys.DefaultKeymap().Term,
+		theme:   classicTheme,
+		header:  header,
+		board:   newBoard(game, screen),
+		stats:   newStatsPanel(game, screen),
+		screen:  screen,
+		top:     tlx,
+		left:    tly,
 	}
+	termGame.index = termGame.keys.Index()
+	header.text = termGame.welcome()
 	return termGame, nil
 }
 
+func (t *TermGame) welcome() string {
+	title := "NEW GAME"
+	if t.game.Phase != core.NotStarted {
+		title = "RESUMED GAME"
+	}
+	return fmt.Sprintf(
+		"%s\nUse %s, %s, %s, %s or drag the board to play / %s for help / %s to quit",
+		title, t.keys.Label(keys.Up), t.keys.Label(keys.Down), t.keys.Label(keys.Left),
+		t.keys.Label(keys.Right), t.keys.Label(keys.Help), t.keys.Label(keys.Quit),
+	)
+}
+
 func (t *TermGame) updateHeader(outcome core.Outcome) {
+	// the summary covers the board, which is redrawn once it is hidden
+	hide := t.summary && outcome == core.Continue
+	t.summary = outcome != core.Continue
+
 	switch outcome {
 	case core.Continue:
 		t.header.text = fmt.Sprintf(
-			"%s\nScore: %d / Undos %d",
-			t.game.Player, t.game.Score(), t.game.UndosLeft(),
+			"%s\nScore: %d / Undos %d / Seed %d",
+			t.game.Player, t.game.Score(), t.game.UndosLeft(), t.game.Seed(),
 		)
-		t.header.style = whiteOnBlue
+		t.header.style = t.theme.info
 	case core.GameOverWin:
-		t.header.text = fmt.Sprintf("%s WINS! Score: %d\nPress Esc to exit", t.game.Player, t.game.Score())
-		t.header.style = whiteOnGreen
+		t.showSummary(
+			fmt.Sprintf("%s WINS!", t.game.Player),
+			fmt.Sprintf("Press Enter to keep going / %s to exit", t.keys.Label(keys.Quit)),
+		)
+		t.header.style = t.theme.success
 	case core.GameOver:
-		t.header.text = "GAME OVER! Score: 0\nPress Esc to exit"
-		t.header.style = whiteOnRed
+		t.showSummary("GAME OVER! No moves left", t.endPrompt())
+		t.header.style = t.theme.failure
+		if t.game.Endless() {
+			t.header.style = t.theme.success
+		}
+	case core.GameQuit:
+		t.showSummary("GAME QUIT", t.endPrompt())
+		t.header.style = t.theme.failure
+	}
+
+	if hide {
+		t.redrawComponents()
+		return
+	}
+	t.redrawHeader()
+	t.redrawSummary()
+}
+
+// puts the title and the prompt in the header,
+// the summary of the game is shown on top of the board
+func (t *TermGame) showSummary(title string, prompt string) {
+	t.header.text = fmt.Sprintf("%s%s\n%s", title, t.highScoreNote(), prompt)
+}
+
+func (t *TermGame) summaryLines() []string {
+	sum := t.game.Summary()
+	return []string{
+		"SUMMARY",
+		"",
+		fmt.Sprintf("Score: %d", sum.Score),
+		fmt.Sprintf("Highest block: %d", sum.Highest),
+		fmt.Sprintf("Moves: %d", sum.Moves),
+		fmt.Sprintf("Merges: %d", sum.Merges),
+		fmt.Sprintf("Undos used: %d", sum.UndosUsed),
+		fmt.Sprintf("Time: %v", sum.Duration.Round(time.Second)),
+	}
+}
+
+func (t *TermGame) redrawSummary() {
+	if !t.summary || t.tooSmall {
+		return
+	}
+	t.drawOverlay(t.summaryLines(), t.theme.info)
+}
+
+// the prompt shown after the game ends
+func (t *TermGame) endPrompt() string {
+	return fmt.Sprintf("Press %s to exit / %s for a new game", t.keys.Label(keys.Quit), t.keys.Label(keys.NewGame))
+}
+
+func (t *TermGame) highScoreNote() string {
+	if t.rank == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" / New high score! Rank #%d", t.rank)
+}
+
+// shows the best move in the header, along with the points each move
+// would score right away and its expected value (in thousands)
+func (t *TermGame) showHint() {
+	advice := t.advisor.Advise(t.game)
+	if !advice.Any {
+		t.header.text = "HINT: no moves left\n"
+		t.header.style = t.theme.notice
+		t.redrawHeader()
+		return
+	}
+
+	estimates := make([]string, 0, len(advice.Directions))
+	for _, d := range advice.Directions {
+		if !d.Valid {
+			estimates = append(estimates, fmt.Sprintf("%v: -", d.Dir))
+			continue
+		}
+		estimates = append(estimates, fmt.Sprintf("%v: +%d (%.0fk)", d.Dir, d.Gained, d.Value/1000))
 	}
 
+	t.header.text = fmt.Sprintf(
+		"HINT: push %s\n%s",
+		strings.ToUpper(advice.Best.String()), strings.Join(estimates, " / "),
+	)
+	t.header.style = t.theme.notice
 	t.redrawHeader()
 }
 
-func (t *TermGame) promptConfirmQuit() {
-	t.header.text = "Are you sure you want to quit?\nConfirm by pressing Esc or press any other key to continue"
-	t.header.style = whiteOnRed
+func (t *TermGame) promptConfirm(kind core.ActionKind) {
+	t.header.text = fmt.Sprintf(
+		"Are you sure you want to quit?\nPress %s or click Quit again to confirm, any other key to continue",
+		t.keys.Label(keys.Quit),
+	)
+	if kind == core.NewGameAction {
+		t.header.text = fmt.Sprintf(
+			"Are you sure you want to start a new game?\nPress %s or click New Game again to confirm, any other key to continue",
+			t.keys.Label(keys.NewGame),
+		)
+	}
+	t.header.style = t.theme.failure
 	t.redrawHeader()
 }
 
 func (t *TermGame) redrawHeader() {
+	if t.tooSmall || !t.headerFits() {
+		// the layout depends on the width of the header
+		t.redrawComponents()
+		return
+	}
+
+	// clear what is left of a longer text
+	cols, _ := t.screen.Size()
+	drawRect(cols-t.left, headerHeight, t.refx, t.left, t.screen, t.theme.screen)
+
 	for i, str := range strings.Split(t.header.text, "\n") {
-		drawRect(t.header.width, 1 /* height */, t.refx+i, t.refy, t.screen, t.header.style)
+		width := t.header.width
+		if n := len([]rune(str)); n > width {
+			width = n
+		}
+		drawRect(width, 1 /* height */, t.refx+i, t.refy, t.screen, t.header.style)
 		drawString(str, t.refx+i, t.refy, t.screen, t.header.style)
 	}
 }
 
-func (t *TermGame) waitEsc() {
+// waits for one of the keys and returns it
+func (t *TermGame) waitKey(want ...tcell.Key) tcell.Key {
 	for {
 		switch ev := t.screen.PollEvent().(type) {
 		case *tcell.EventResize:
 			t.redrawComponents()
 		case *tcell.EventKey:
-			if ev.Key() == tcell.KeyEscape {
-				return
+			for _, key := range want {
+				if ev.Key() == key {
+					return key
+				}
 			}
 		}
 	}
 }
 
+// shows or hides the stats panel, the board is resized to make room for it
+func (t *TermGame) toggleStats() {
+	t.stats.visible = !t.stats.visible
+	t.board.margin = 0
+	if t.stats.visible {
+		t.board.margin = statsPanelGap + statsPanelWidth
+	}
+	t.redrawComponents()
+}
+
 func (t *TermGame) redrawComponents() {
+	t.screen.Clear()
+	t.tooSmall = !t.fitScreen()
+	if t.tooSmall {
+		t.drawTooSmall()
+		t.screen.Sync()
+		return
+	}
 	t.redrawHeader()
+	t.redrawToolbar()
 	t.board.redraw()
+	t.stats.redraw()
+	t.redrawSummary()
+	t.redrawHelp()
 	t.screen.Sync()
 }
 
-func (t *TermGame) Run() error {
-	var (
-		outcome       = core.Continue
-		quitRequested = false
-	)
+// redraws the game after an action of the player
+func (t *TermGame) update(a core.Action, ok bool) {
+	if a.Kind == core.NewGameAction {
+		t.rank = 0
+		t.redrawComponents()
+	}
+	if (ok || a.Kind == core.PushAction) && !t.tooSmall {
+		t.redrawMove(a, ok)
+		t.stats.redraw()
+	}
 
-	if t.game.Phase != core.NotStarted {
-		return fmt.Errorf("terminal game has already started")
+	outcome := t.game.Outcome()
+	if outcome != core.Continue {
+		// high scores are best-effort, like saving the game
+		if rank, err := t.game.RecordHighScore(); err == nil {
+			t.rank = rank
+		}
+	}
+
+	t.updateHeader(outcome)
+	t.screen.Show()
+}
+
+// redraws the board after the action, animating pushes and redos
+func (t *TermGame) redrawMove(a core.Action, ok bool) {
+	if t.tooSmall {
+		return
+	}
+	if ok && (a.Kind == core.PushAction || a.Kind == core.RedoAction) {
+		t.board.animate(t.game.Transition(), t.animation)
+		return
 	}
+	t.board.redraw()
+}
+
+// SetAnimation sets the duration of the animation of a move,
+// 0 turns animations off.
+func (t *TermGame) SetAnimation(d time.Duration) {
+	t.animation = d
+}
 
+func (t *TermGame) Run() error {
+	if t.game.Phase == core.Finished {
+		return fmt.Errorf("terminal game has already finished")
+	}
+
+	t.toolbar = newToolbar()
 	t.redrawComponents()
 
-	// event loop
-	for outcome == core.Continue {
+	h := &human{t: t}
+	for {
+		outcome := core.Play(t.game, h, t.update)
+		if outcome == core.GameQuit && t.game.Phase == core.Finished {
+			// quit right after winning, the summary was already shown
+			break
+		}
+		t.updateHeader(outcome)
+		t.screen.Show()
+
+		if !h.waitNewGame() {
+			break
+		}
+		t.update(core.Action{Kind: core.NewGameAction}, t.game.Apply(core.Action{Kind: core.NewGameAction}))
+	}
+
+	t.screen.Fini()
+	return nil
+}
+
+// human is the player at the terminal, using the keyboard or the mouse
+type human struct {
+	t       *TermGame
+	mouse   mouse
+	pending core.ActionKind // action waiting to be confirmed
+	confirm bool            // whether an action is waiting to be confirmed
+}
+
+// Act handles events until the player chooses an action, showing hints
+// and asking to confirm quitting or starting a new game along the way.
+func (h *human) Act(v core.View) core.Action {
+	t := h.t
+	for {
+		var (
+			a  core.Action
+			ok bool
+		)
 		switch ev := t.screen.PollEvent().(type) {
 		case *tcell.EventResize:
 			t.redrawComponents()
+		case *tcell.EventKey:
+			a, ok = h.key(ev)
+		case *tcell.EventMouse:
+			a, ok = h.mouse.handle(t, ev)
+		}
+		if !ok {
+			continue
+		}
+		if t.tooSmall {
+			// the game can't be seen, but it can be quit
+			h.confirm = false
+			if a.Kind == core.QuitAction {
+				return a
+			}
+			// the status may fit where a longer text didn't
+			t.updateHeader(v.Outcome())
+			t.screen.Show()
 			continue
+		}
 
-		case *tcell.EventKey:
-			switch ev.Key() {
-			case tcell.KeyEscape:
-				if quitRequested {
-					outcome = core.GameOver
-				} else { // ask for second Esc to quit
-					quitRequested = true
-					t.promptConfirmQuit()
-					t.screen.Show()
-					continue
-				}
-			case tcell.KeyRight:
-				outcome = t.board.push(core.Right)
-			case tcell.KeyLeft:
-				outcome = t.board.push(core.Left)
-			case tcell.KeyUp:
-				outcome = t.board.push(core.Up)
-			case tcell.KeyDown:
-				outcome = t.board.push(core.Down)
-			case tcell.KeyCtrlU:
-				t.board.undo()
+		won := v.Outcome() == core.GameOverWin
+		switch a.Kind {
+		case core.KeepGoingAction:
+			if !won {
+				continue
 			}
+		case core.QuitAction, core.NewGameAction:
+			if !won && (!h.confirm || h.pending != a.Kind) {
+				h.confirm, h.pending = true, a.Kind
+				t.promptConfirm(a.Kind)
+				t.screen.Show()
+				continue
+			}
+		default:
+			if won {
+				continue
+			}
+		}
 
-			t.updateHeader(outcome)
+		h.confirm = false
+		return a
+	}
+}
+
+// translates the key into an action, ok is false for keys
+// handled by the interface itself
+func (h *human) key(ev *tcell.EventKey) (a core.Action, ok bool) {
+	t := h.t
+	if t.help {
+		// any key closes the help overlay
+		t.help = false
+		t.redrawComponents()
+		return a, false
+	}
+	if ev.Key() == tcell.KeyEnter {
+		return core.Action{Kind: core.KeepGoingAction}, true
+	}
+
+	action, bound := t.index[keyName(ev)]
+	if bound {
+		if a, ok := keys.GameAction(action); ok {
+			return a, true
+		}
+		switch action {
+		case keys.Hint:
+			h.confirm = false
+			t.showHint()
+			t.screen.Show()
+			return a, false
+		case keys.Stats:
+			t.toggleStats()
+			return a, false
+		case keys.Theme:
+			h.confirm = false
+			t.nextTheme()
+			return a, false
+		case keys.Help:
+			h.confirm = false
+			t.help = true
+			t.updateHeader(t.game.Outcome())
+			t.redrawHelp()
 			t.screen.Show()
-			quitRequested = false
+			return a, false
 		}
 	}
 
-	t.waitEsc()
-	t.screen.Fini()
-	return nil
+	// any other key dismisses the hint or the confirmation prompt
+	h.confirm = false
+	t.updateHeader(t.game.Outcome())
+	t.screen.Show()
+	return a, false
+}
+
+// waits until the player exits, or starts a new game (the result)
+func (h *human) waitNewGame() bool {
+	t := h.t
+	for {
+		switch ev := t.screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			t.redrawComponents()
+		case *tcell.EventKey:
+			if action, ok := t.index[keyName(ev)]; ok {
+				switch action {
+				case keys.Quit:
+					return false
+				case keys.NewGame:
+					return true
+				}
+			}
+		case *tcell.EventMouse:
+			if a, ok := h.mouse.handle(t, ev); ok {
+				switch a.Kind {
+				case core.NewGameAction:
+					return true
+				case core.QuitAction:
+					return false
+				}
+			}
+		}
+	}
 }
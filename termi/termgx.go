@@ -11,7 +11,14 @@ const (
 	colorDarkGray      = tcell.Color242
 )
 
+// size of the digits of bitmap, in pixels
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+)
+
 var (
+	// pixels of the digits, bit 3*row+col is set for the pixels drawn
 	bitmap = map[int]uint16{
 		0: 0x7b6f,
 		1: 0x4926,
@@ -24,46 +31,16 @@ var (
 		8: 0x7bef,
 		9: 0x79ef,
 	}
-
-	emptyCellStyle = tcell.StyleDefault.
-			Background(colorLightGray)
-
-	whiteOnBlackDefault = tcell.StyleDefault.
-				Background(tcell.ColorBlack).
-				Foreground(tcell.ColorWhite)
-
-	whiteOnBlue = tcell.StyleDefault.
-			Background(tcell.ColorBlue).
-			Foreground(tcell.ColorWhite)
-
-	whiteOnGreen = tcell.StyleDefault.
-			Background(tcell.ColorGreen).
-			Foreground(tcell.ColorWhite)
-
-	whiteOnRed = tcell.StyleDefault.
-			Background(tcell.ColorRed).
-			Foreground(tcell.ColorWhite)
 )
 
-// assumes n > 0
-// ignore return value, it is used internally
-func drawNumber(n int, tlx int, tly int, s tcell.Screen, st tcell.Style) int {
-	if n/10 > 0 {
-		tly = drawNumber(n/10, tlx, tly, s, st) + digitFieldWidth - 1
-	}
-	digit := n % 10
-	for i := 0; i < 16; i++ {
-		if bitmap[digit]&(1<<i) != 0 {
-			s.SetContent(tly+i%3+1, tlx+i/3+1, ' ', nil, st)
-		}
-	}
-	return tly
+func drawRect(w int, h int, tlx int, tly int, s tcell.Screen, st tcell.Style) {
+	fillRect(w, h, tlx, tly, ' ', s, st)
 }
 
-func drawRect(w int, h int, tlx int, tly int, s tcell.Screen, st tcell.Style) {
+func fillRect(w int, h int, tlx int, tly int, r rune, s tcell.Screen, st tcell.Style) {
 	for x := 0; x < h; x++ {
 		for y := 0; y < w; y++ {
-			s.SetContent(tly+y, tlx+x, ' ', nil, st)
+			s.SetContent(tly+y, tlx+x, r, nil, st)
 		}
 	}
 }
@@ -0,0 +1,505 @@
+package termi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/cicovic-andrija/2048/keys"
+	"github.com/gdamore/tcell"
+)
+
+// DefaultTheme is the name of the theme used unless chosen otherwise.
+const DefaultTheme = "classic"
+
+// colors of a block: background, and the digits drawn on it
+type blockColors struct {
+	bg tcell.Color
+	fg tcell.Color
+}
+
+// theme holds the colors of everything drawn on the screen
+type theme struct {
+	name string
+
+	screen tcell.Style // background of the screen
+	board  tcell.Color // gaps between the blocks
+	empty  tcell.Color // empty cells
+
+	blocks []blockColors // of blocks 2, 4, 8...
+	large  blockColors   // of blocks beyond the ones above
+
+	info    tcell.Style // header during the game, stats panel
+	success tcell.Style // header after a win
+	failure tcell.Style // header after a loss, confirmations
+	notice  tcell.Style // hints, help
+	button  tcell.Style
+}
+
+// index of the block in theme.blocks
+func blockIndex(val int) int {
+	i := 0
+	for b := 2; b < val; b *= 2 {
+		i++
+	}
+	return i
+}
+
+func (th *theme) blockColors(val int) blockColors {
+	if i := blockIndex(val); i < len(th.blocks) {
+		return th.blocks[i]
+	}
+	return th.large
+}
+
+func styleOf(bg tcell.Color, fg tcell.Color) tcell.Style {
+	return tcell.StyleDefault.Background(bg).Foreground(fg)
+}
+
+var classicTheme = &theme{
+	name:   "classic",
+	screen: styleOf(tcell.ColorBlack, tcell.ColorWhite),
+	board:  colorGray,
+	empty:  colorLightGray,
+	blocks: []blockColors{
+		{colorEggShellWhite, colorDarkGray},
+		{tcell.Color230, colorDarkGray},
+		{tcell.Color222, tcell.ColorWhite},
+		{tcell.Color215, tcell.ColorWhite},
+		{tcell.Color209, tcell.ColorWhite},
+		{tcell.Color196, tcell.ColorWhite},
+		{tcell.Color228, tcell.ColorWhite},
+		{tcell.Color227, tcell.ColorWhite},
+		{tcell.Color226, tcell.ColorWhite},
+		{tcell.Color221, tcell.ColorWhite},
+		{tcell.Color220, tcell.ColorWhite},
+		{tcell.Color212, tcell.ColorWhite},
+		{tcell.Color211, tcell.ColorWhite},
+		{tcell.Color141, tcell.ColorWhite},
+		{tcell.Color135, tcell.ColorWhite},
+		{tcell.Color99, tcell.ColorWhite},
+		{tcell.Color93, tcell.ColorWhite},
+	},
+	large:   blockColors{tcell.Color53, tcell.ColorWhite},
+	info:    styleOf(tcell.ColorBlue, tcell.ColorWhite),
+	success: styleOf(tcell.ColorGreen, tcell.ColorWhite),
+	failure: styleOf(tcell.ColorRed, tcell.ColorWhite),
+	notice:  styleOf(tcell.ColorYellow, tcell.ColorBlack),
+	button:  styleOf(colorDarkGray, tcell.ColorWhite),
+}
+
+var darkTheme = &theme{
+	name:   "dark",
+	screen: styleOf(tcell.ColorBlack, tcell.Color252),
+	board:  tcell.Color236,
+	empty:  tcell.Color238,
+	blocks: []blockColors{
+		{tcell.Color240, tcell.Color255},
+		{tcell.Color243, tcell.Color255},
+		{tcell.Color130, tcell.Color255},
+		{tcell.Color166, tcell.Color255},
+		{tcell.Color160, tcell.Color255},
+		{tcell.Color124, tcell.Color255},
+		{tcell.Color136, tcell.Color255},
+		{tcell.Color142, tcell.Color255},
+		{tcell.Color100, tcell.Color255},
+		{tcell.Color64, tcell.Color255},
+		{tcell.Color28, tcell.Color255},
+		{tcell.Color25, tcell.Color255},
+		{tcell.Color61, tcell.Color255},
+		{tcell.Color54, tcell.Color255},
+		{tcell.Color90, tcell.Color255},
+		{tcell.Color125, tcell.Color255},
+		{tcell.Color89, tcell.Color255},
+	},
+	large:   blockColors{tcell.Color52, tcell.Color255},
+	info:    styleOf(tcell.Color24, tcell.Color255),
+	success: styleOf(tcell.Color22, tcell.Color255),
+	failure: styleOf(tcell.Color88, tcell.Color255),
+	notice:  styleOf(tcell.Color178, tcell.ColorBlack),
+	button:  styleOf(tcell.Color238, tcell.Color250),
+}
+
+// the 16 basic colors, black or white digits on all of them
+var highContrastTheme = &theme{
+	name:   "high-contrast",
+	screen: styleOf(tcell.ColorBlack, tcell.ColorWhite),
+	board:  tcell.ColorWhite,
+	empty:  tcell.ColorBlack,
+	blocks: []blockColors{
+		{tcell.ColorYellow, tcell.ColorBlack},
+		{tcell.ColorAqua, tcell.ColorBlack},
+		{tcell.ColorLime, tcell.ColorBlack},
+		{tcell.ColorFuchsia, tcell.ColorBlack},
+		{tcell.ColorRed, tcell.ColorBlack},
+		{tcell.ColorBlue, tcell.ColorWhite},
+		{tcell.ColorOlive, tcell.ColorWhite},
+		{tcell.ColorTeal, tcell.ColorWhite},
+		{tcell.ColorGreen, tcell.ColorWhite},
+		{tcell.ColorPurple, tcell.ColorWhite},
+		{tcell.ColorMaroon, tcell.ColorWhite},
+		{tcell.ColorNavy, tcell.ColorWhite},
+		{tcell.ColorGray, tcell.ColorWhite},
+	},
+	large:   blockColors{tcell.ColorSilver, tcell.ColorBlack},
+	info:    styleOf(tcell.ColorWhite, tcell.ColorBlack),
+	success: styleOf(tcell.ColorLime, tcell.ColorBlack),
+	failure: styleOf(tcell.ColorRed, tcell.ColorBlack),
+	notice:  styleOf(tcell.ColorYellow, tcell.ColorBlack),
+	button:  styleOf(tcell.ColorSilver, tcell.ColorBlack),
+}
+
+// the dark variant of Ethan Schoonover's palette
+var (
+	solarizedBase03  = tcell.NewHexColor(0x002b36)
+	solarizedBase02  = tcell.NewHexColor(0x073642)
+	solarizedBase01  = tcell.NewHexColor(0x586e75)
+	solarizedBase0   = tcell.NewHexColor(0x839496)
+	solarizedBase1   = tcell.NewHexColor(0x93a1a1)
+	solarizedBase2   = tcell.NewHexColor(0xeee8d5)
+	solarizedBase3   = tcell.NewHexColor(0xfdf6e3)
+	solarizedYellow  = tcell.NewHexColor(0xb58900)
+	solarizedOrange  = tcell.NewHexColor(0xcb4b16)
+	solarizedRed     = tcell.NewHexColor(0xdc322f)
+	solarizedMagenta = tcell.NewHexColor(0xd33682)
+	solarizedViolet  = tcell.NewHexColor(0x6c71c4)
+	solarizedBlue    = tcell.NewHexColor(0x268bd2)
+	solarizedCyan    = tcell.NewHexColor(0x2aa198)
+	solarizedGreen   = tcell.NewHexColor(0x859900)
+)
+
+var solarizedTheme = &theme{
+	name:   "solarized",
+	screen: styleOf(solarizedBase03, solarizedBase0),
+	board:  solarizedBase01,
+	empty:  solarizedBase02,
+	blocks: []blockColors{
+		{solarizedBase2, solarizedBase01},
+		{solarizedBase3, solarizedBase01},
+		{solarizedYellow, solarizedBase3},
+		{solarizedOrange, solarizedBase3},
+		{solarizedRed, solarizedBase3},
+		{solarizedMagenta, solarizedBase3},
+		{solarizedViolet, solarizedBase3},
+		{solarizedBlue, solarizedBase3},
+		{solarizedCyan, solarizedBase3},
+		{solarizedGreen, solarizedBase3},
+		{solarizedBase3, solarizedRed},
+		{solarizedYellow, solarizedBase03},
+		{solarizedOrange, solarizedBase03},
+		{solarizedRed, solarizedBase03},
+		{solarizedMagenta, solarizedBase03},
+		{solarizedViolet, solarizedBase03},
+		{solarizedBlue, solarizedBase03},
+	},
+	large:   blockColors{solarizedBase1, solarizedBase03},
+	info:    styleOf(solarizedBlue, solarizedBase3),
+	success: styleOf(solarizedGreen, solarizedBase3),
+	failure: styleOf(solarizedRed, solarizedBase3),
+	notice:  styleOf(solarizedYellow, solarizedBase03),
+	button:  styleOf(solarizedBase01, solarizedBase2),
+}
+
+// shades of gray, lighter as the blocks grow
+var monochromeTheme = &theme{
+	name:   "monochrome",
+	screen: styleOf(tcell.Color232, tcell.Color252),
+	board:  tcell.Color237,
+	empty:  tcell.Color235,
+	blocks: []blockColors{
+		{tcell.Color239, tcell.Color255},
+		{tcell.Color241, tcell.Color255},
+		{tcell.Color243, tcell.Color255},
+		{tcell.Color245, tcell.Color232},
+		{tcell.Color247, tcell.Color232},
+		{tcell.Color249, tcell.Color232},
+		{tcell.Color251, tcell.Color232},
+		{tcell.Color253, tcell.Color232},
+		{tcell.Color255, tcell.Color232},
+	},
+	large:   blockColors{tcell.Color255, tcell.Color232},
+	info:    styleOf(tcell.Color238, tcell.Color255),
+	success: styleOf(tcell.Color250, tcell.Color232),
+	failure: styleOf(tcell.Color244, tcell.Color255).Bold(true),
+	notice:  styleOf(tcell.Color255, tcell.Color232),
+	button:  styleOf(tcell.Color246, tcell.Color232),
+}
+
+// colors told apart with any kind of color blindness, from the palettes
+// of Masataka Okabe and Kei Ito, and of Paul Tol; also the shades differ,
+// so that neighbouring blocks are never alike
+var colorblindBlocks = []blockColors{
+	{tcell.NewHexColor(0xeeeeee), tcell.NewHexColor(0x555555)},
+	{tcell.NewHexColor(0xf0e442), tcell.ColorBlack},
+	{tcell.NewHexColor(0xe69f00), tcell.ColorBlack},
+	{tcell.NewHexColor(0x56b4e9), tcell.ColorBlack},
+	{tcell.NewHexColor(0x009e73), tcell.ColorWhite},
+	{tcell.NewHexColor(0xd55e00), tcell.ColorWhite},
+	{tcell.NewHexColor(0x0072b2), tcell.ColorWhite},
+	{tcell.NewHexColor(0xcc79a7), tcell.ColorBlack},
+	{tcell.NewHexColor(0xddcc77), tcell.ColorBlack},
+	{tcell.NewHexColor(0x88ccee), tcell.ColorBlack},
+	{tcell.NewHexColor(0x332288), tcell.ColorWhite},
+	{tcell.NewHexColor(0x117733), tcell.ColorWhite},
+	{tcell.NewHexColor(0x882255), tcell.ColorWhite},
+	{tcell.NewHexColor(0x44aa99), tcell.ColorBlack},
+	{tcell.NewHexColor(0x999933), tcell.ColorBlack},
+	{tcell.NewHexColor(0xaa4499), tcell.ColorWhite},
+	{tcell.NewHexColor(0xcc6677), tcell.ColorBlack},
+}
+
+var colorblindTheme = &theme{
+	name:    "colorblind",
+	screen:  styleOf(tcell.ColorBlack, tcell.ColorWhite),
+	board:   tcell.NewHexColor(0x999999),
+	empty:   tcell.NewHexColor(0xbbbbbb),
+	blocks:  colorblindBlocks,
+	large:   blockColors{tcell.ColorBlack, tcell.ColorWhite},
+	info:    styleOf(tcell.NewHexColor(0x0072b2), tcell.ColorWhite),
+	success: styleOf(tcell.NewHexColor(0x009e73), tcell.ColorWhite),
+	failure: styleOf(tcell.NewHexColor(0xd55e00), tcell.ColorWhite),
+	notice:  styleOf(tcell.NewHexColor(0xf0e442), tcell.ColorBlack),
+	button:  styleOf(tcell.NewHexColor(0x555555), tcell.ColorWhite),
+}
+
+var colorblindDarkTheme = &theme{
+	name:    "colorblind-dark",
+	screen:  styleOf(tcell.ColorBlack, tcell.Color252),
+	board:   tcell.NewHexColor(0x222222),
+	empty:   tcell.NewHexColor(0x3a3a3a),
+	blocks:  colorblindBlocks,
+	large:   blockColors{tcell.ColorWhite, tcell.ColorBlack},
+	info:    colorblindTheme.info,
+	success: colorblindTheme.success,
+	failure: colorblindTheme.failure,
+	notice:  colorblindTheme.notice,
+	button:  styleOf(tcell.NewHexColor(0x3a3a3a), tcell.Color252),
+}
+
+// built-in themes followed by the ones loaded by LoadThemes,
+// in the order in which they are cycled through
+var themes = []*theme{
+	classicTheme, darkTheme, highContrastTheme, solarizedTheme, monochromeTheme,
+	colorblindTheme, colorblindDarkTheme,
+}
+
+func findTheme(name string) *theme {
+	for _, th := range themes {
+		if th.name == name {
+			return th
+		}
+	}
+	return nil
+}
+
+// ThemeNames returns the names of the themes.
+func ThemeNames() []string {
+	names := make([]string, len(themes))
+	for i, th := range themes {
+		names[i] = th.name
+	}
+	return names
+}
+
+// CheckTheme reports an error if there is no theme with the name.
+func CheckTheme(name string) error {
+	if findTheme(name) == nil {
+		return fmt.Errorf("invalid theme %q, must be one of %s", name, strings.Join(ThemeNames(), ", "))
+	}
+	return nil
+}
+
+// colors as written in a theme file
+type colorsFile struct {
+	Bg string `json:"bg"`
+	Fg string `json:"fg"`
+}
+
+// themeFile is a theme as written in a theme file, the colors left out
+// are taken from the base theme
+type themeFile struct {
+	Name    string                `json:"name"`
+	Base    string                `json:"base"` // classic if empty
+	Screen  *colorsFile           `json:"screen"`
+	Board   string                `json:"board"`
+	Empty   string                `json:"empty"`
+	Blocks  map[string]colorsFile `json:"blocks"` // by block value
+	Large   *colorsFile           `json:"large"`
+	Info    *colorsFile           `json:"info"`
+	Success *colorsFile           `json:"success"`
+	Failure *colorsFile           `json:"failure"`
+	Notice  *colorsFile           `json:"notice"`
+	Button  *colorsFile           `json:"button"`
+}
+
+// parseColor accepts a W3C color name, an RGB value in the form #rrggbb,
+// or the number of a color of the 256-color palette
+func parseColor(s string) (tcell.Color, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 || n > 255 {
+			return 0, fmt.Errorf("invalid color %q: not in the palette", s)
+		}
+		return tcell.Color(n), nil
+	}
+	if c := tcell.GetColor(strings.ToLower(s)); c != tcell.ColorDefault {
+		return c, nil
+	}
+	return 0, fmt.Errorf("invalid color %q", s)
+}
+
+func (c *colorsFile) parse(bg *tcell.Color, fg *tcell.Color) (err error) {
+	if c.Bg != "" {
+		if *bg, err = parseColor(c.Bg); err != nil {
+			return err
+		}
+	}
+	if c.Fg != "" {
+		if *fg, err = parseColor(c.Fg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *colorsFile) parseStyle(st *tcell.Style) error {
+	if c == nil {
+		return nil
+	}
+	fg, bg, attrs := st.Decompose()
+	if err := c.parse(&bg, &fg); err != nil {
+		return err
+	}
+	*st = styleOf(bg, fg).Bold(attrs&tcell.AttrBold != 0).Reverse(attrs&tcell.AttrReverse != 0)
+	return nil
+}
+
+func (f *themeFile) theme() (*theme, error) {
+	if f.Name == "" {
+		return nil, fmt.Errorf("theme without a name")
+	}
+	if findTheme(f.Name) != nil {
+		return nil, fmt.Errorf("theme %s already exists", f.Name)
+	}
+	if f.Base == "" {
+		f.Base = DefaultTheme
+	}
+	base := findTheme(f.Base)
+	if base == nil {
+		return nil, fmt.Errorf("theme %s: unknown base theme %s", f.Name, f.Base)
+	}
+
+	th := *base
+	th.name = f.Name
+	th.blocks = append([]blockColors(nil), base.blocks...)
+
+	styles := []struct {
+		colors *colorsFile
+		style  *tcell.Style
+	}{
+		{f.Screen, &th.screen},
+		{f.Info, &th.info},
+		{f.Success, &th.success},
+		{f.Failure, &th.failure},
+		{f.Notice, &th.notice},
+		{f.Button, &th.button},
+	}
+	for _, s := range styles {
+		if err := s.colors.parseStyle(s.style); err != nil {
+			return nil, fmt.Errorf("theme %s: %v", f.Name, err)
+		}
+	}
+
+	var err error
+	if f.Board != "" {
+		if th.board, err = parseColor(f.Board); err != nil {
+			return nil, fmt.Errorf("theme %s: %v", f.Name, err)
+		}
+	}
+	if f.Empty != "" {
+		if th.empty, err = parseColor(f.Empty); err != nil {
+			return nil, fmt.Errorf("theme %s: %v", f.Name, err)
+		}
+	}
+	if f.Large != nil {
+		if err := f.Large.parse(&th.large.bg, &th.large.fg); err != nil {
+			return nil, fmt.Errorf("theme %s: %v", f.Name, err)
+		}
+	}
+
+	for val, colors := range f.Blocks {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 2 || n&(n-1) != 0 {
+			return nil, fmt.Errorf("theme %s: invalid block %q", f.Name, val)
+		}
+		i := blockIndex(n)
+		for len(th.blocks) <= i {
+			th.blocks = append(th.blocks, th.large)
+		}
+		c := &th.blocks[i]
+		if err := colors.parse(&c.bg, &c.fg); err != nil {
+			return nil, fmt.Errorf("theme %s: %v", f.Name, err)
+		}
+	}
+	return &th, nil
+}
+
+// LoadThemes reads user-defined themes from a JSON file holding a list of
+// themes, and adds them to the built-in ones. For example:
+//
+//	[{"name": "ocean", "base": "dark", "board": "#003355", "blocks": {"2048": {"bg": "gold", "fg": "black"}}}]
+//
+// Colors are W3C names, #rrggbb values or numbers of the 256-color palette.
+func LoadThemes(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var files []themeFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return fmt.Errorf("invalid theme file %s: %v", path, err)
+	}
+	for i := range files {
+		th, err := files[i].theme()
+		if err != nil {
+			return fmt.Errorf("invalid theme file %s: %v", path, err)
+		}
+		themes = append(themes, th)
+	}
+	return nil
+}
+
+func (t *TermGame) setTheme(th *theme) {
+	t.theme = th
+	t.board.theme = th
+	t.stats.theme = th
+	t.screen.SetStyle(th.screen)
+}
+
+// switches to the next theme, and shows its name in the header
+func (t *TermGame) nextTheme() {
+	next := themes[0]
+	for i, th := range themes {
+		if th == t.theme && i+1 < len(themes) {
+			next = themes[i+1]
+		}
+	}
+	t.setTheme(next)
+
+	t.header.text = fmt.Sprintf("THEME: %s\nPress %s for the next theme", next.name, t.keys.Label(keys.Theme))
+	t.header.style = next.notice
+	t.redrawComponents()
+}
+
+// SetTheme changes the colors of the game to the theme with the name.
+func (t *TermGame) SetTheme(name string) error {
+	th := findTheme(name)
+	if th == nil {
+		return CheckTheme(name)
+	}
+	t.setTheme(th)
+	t.header.style = th.success
+	return nil
+}
@@ -6,19 +6,30 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/cicovic-andrija/2048/core"
+	"github.com/cicovic-andrija/2048/keys"
+	"github.com/cicovic-andrija/2048/solver"
 )
 
+// actions of the text interface, which has no statistics panel or colors
+var textActions = []keys.Action{
+	keys.Up, keys.Down, keys.Left, keys.Right,
+	keys.Undo, keys.Redo, keys.Hint, keys.NewGame, keys.Quit, keys.Help,
+}
+
 var (
 	textiHorizLine    string
 	textiScoreLineFmt string
 )
 
 // assumes board size is in limits
-func buildTextiParts(playerName string, boardSize int) {
-	textiHorizLine = "\n+" + strings.Repeat("------+", boardSize) + "\n"
-	textiScoreLineFmt = playerName + "'s score: %d, undos left: %d"
+func buildTextiParts(playerName string, boardCols int) {
+	textiHorizLine = "\n+" + strings.Repeat("--------+", boardCols) + "\n"
+	textiScoreLineFmt = playerName + "'s score: %d, undos left: %d, seed: %d"
 }
 
 func drawBoard(g *core.Game) {
@@ -30,11 +41,11 @@ func drawBoard(g *core.Game) {
 	}
 
 	var str strings.Builder
-	str.WriteString(fmt.Sprintf(textiScoreLineFmt, g.Score(), g.UndosLeft()))
+	str.WriteString(fmt.Sprintf(textiScoreLineFmt, g.Score(), g.UndosLeft(), g.Seed()))
 	str.WriteString(textiHorizLine)
-	for i := 0; i < g.Size; i++ {
-		for j := 0; j < g.Size; j++ {
-			str.WriteString(fmt.Sprintf("| %-4s ", tostring(g.Block(i, j))))
+	for i := 0; i < g.Rows; i++ {
+		for j := 0; j < g.Cols; j++ {
+			str.WriteString(fmt.Sprintf("| %-6s ", tostring(g.Block(i, j))))
 		}
 		str.WriteString("|" + textiHorizLine)
 	}
@@ -42,62 +53,160 @@ func drawBoard(g *core.Game) {
 	fmt.Print(str.String())
 }
 
-func NewTextGame(player string, size int, target int, undos int) error {
-	game, err := core.NewGame(player, size, target, undos)
-	if err != nil {
-		return fmt.Errorf("error in game initialization: %v", err)
+// keys of the text interface are single characters, read from
+// the standard input
+func validKey(key string) bool {
+	r, n := utf8.DecodeRuneInString(key)
+	return n == len(key) && unicode.IsPrint(r) && !unicode.IsSpace(r)
+}
+
+// CheckKeys validates the key bindings of the text interface.
+func CheckKeys(b keys.Bindings) error {
+	if err := b.Check(textActions, validKey); err != nil {
+		return fmt.Errorf("text key bindings: %v", err)
+	}
+	return nil
+}
+
+func printControls(b keys.Bindings) {
+	controls := make([]string, 0, len(textActions))
+	for _, a := range textActions {
+		if len(b[a]) > 0 {
+			controls = append(controls, fmt.Sprintf("'%s' (%s)", strings.Join(b[a], "', '"), a))
+		}
 	}
+	fmt.Println("Controls: " + strings.Join(controls, " / "))
+}
 
-	buildTextiParts(player, size)
+func printHelp(b keys.Bindings) {
+	fmt.Println("Key bindings:")
+	for _, a := range textActions {
+		fmt.Printf("  %-8s %s\n", b.Label(a), a.Description())
+	}
+	fmt.Println("  Commands are followed by Enter, several can be typed on one line.")
+}
+
+func NewTextGame(game *core.Game, bindings keys.Bindings) error {
+	if game.Phase == core.Finished {
+		return fmt.Errorf("text game has already finished")
+	}
+	if err := CheckKeys(bindings); err != nil {
+		return err
+	}
 
-	reader := bufio.NewReader(os.Stdin)
-	outcome := core.Continue
+	buildTextiParts(game.Player, game.Cols)
 
-	fmt.Println("Controls: 'w' (Up) / 'a' (Left) / 'd' (Right) / 's' (Down) / 'u' (Undo) / 'q' (Quit)")
+	printControls(bindings)
 	drawBoard(game)
-	for outcome == core.Continue {
 
+	player := &keyboard{
+		reader:  bufio.NewReader(os.Stdin),
+		player:  game.Player,
+		keys:    bindings,
+		index:   bindings.Index(),
+		advisor: solver.New(solver.DefaultDepth),
+	}
+	outcome := core.Play(game, player, func(a core.Action, ok bool) {
+		switch {
+		case a.Kind == core.UndoAction && !ok:
+			fmt.Println("Can't undo: no undos left / no move to undo.")
+		case a.Kind == core.RedoAction && !ok:
+			fmt.Println("Can't redo: no undone move to redo.")
+		default:
+			drawBoard(game)
+		}
+
+		if game.Outcome() != core.Continue {
+			if rank, err := game.RecordHighScore(); err == nil && rank > 0 {
+				fmt.Printf("New high score! Rank #%d (%v)\n", rank, game.BoardKey())
+			}
+		}
+	})
+	title := "GAME QUIT"
+	switch {
+	case outcome == core.GameOver:
+		title = "GAME OVER! No moves left"
+	case game.Phase == core.Finished:
+		title = game.Player + " WINS!" // and chose not to keep going
+	}
+	printSummary(title, game.Summary())
+
+	return nil
+}
+
+func printSummary(title string, sum core.Summary) {
+	fmt.Printf("===\n%s\n", title)
+	fmt.Printf("Score: %d\n", sum.Score)
+	fmt.Printf("Highest block: %d\n", sum.Highest)
+	fmt.Printf("Moves: %d\n", sum.Moves)
+	fmt.Printf("Merges: %d\n", sum.Merges)
+	fmt.Printf("Undos used: %d\n", sum.UndosUsed)
+	fmt.Printf("Time: %v\n===\n", sum.Duration.Round(time.Second))
+}
+
+// keyboard is the player typing commands on the standard input
+type keyboard struct {
+	reader  *bufio.Reader
+	player  string
+	keys    keys.Bindings
+	index   map[string]keys.Action // actions by normalized key
+	advisor *solver.Solver
+}
+
+func (k *keyboard) Act(v core.View) core.Action {
+	if v.Outcome() == core.GameOverWin {
+		fmt.Printf("===\n%s WINS! Score: %d\n===\n", k.player, v.Score())
+		if askKeepGoing(k.reader) {
+			return core.Action{Kind: core.KeepGoingAction}
+		}
+		return core.Action{Kind: core.QuitAction}
+	}
+
+	for {
 		// read a command
-		char, _, err := reader.ReadRune()
+		char, _, err := k.reader.ReadRune()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return core.Action{Kind: core.QuitAction}
+		}
+
+		if char == '\n' || char == '\r' {
 			continue
 		}
 
-		// execute the command
-		switch char {
-		case 'd', 'D', 'l', 'L':
-			outcome = game.Push(core.Right)
-			drawBoard(game)
-		case 'a', 'A', 'h', 'H':
-			outcome = game.Push(core.Left)
-			drawBoard(game)
-		case 'w', 'W', 'k', 'K':
-			outcome = game.Push(core.Up)
-			drawBoard(game)
-		case 's', 'S', 'j', 'J':
-			outcome = game.Push(core.Down)
-			drawBoard(game)
-		case 'u', 'U':
-			if ok := game.Undo(); !ok {
-				fmt.Println("Can't undo: no undos left / second undo in a row / first move.")
-				break
+		action, ok := k.index[keys.Normalize(string(char))]
+		if !ok {
+			fmt.Printf("Invalid command, '%s' for help.\n", k.keys.Label(keys.Help))
+			continue
+		}
+		if a, ok := keys.GameAction(action); ok {
+			return a
+		}
+		switch action {
+		case keys.Hint:
+			if dir, ok := k.advisor.BestMove(v); ok {
+				fmt.Printf("Hint: push %s\n", dir)
+			} else {
+				fmt.Println("Hint: no moves left")
 			}
-			drawBoard(game)
-		case 'q', 'Q':
-			outcome = core.GameOver
-		case '\n', '\r':
-			// ignore
-		default:
-			fmt.Println("Invalid command.")
+		case keys.Help:
+			printHelp(k.keys)
 		}
 	}
+}
 
-	if outcome == core.GameOverWin {
-		fmt.Printf("===\n%s WINS! Score: %d\n===\n", game.Player, game.Score())
-	} else {
-		fmt.Printf("===\nGAME OVER! Score: 0\n===\n")
+func askKeepGoing(reader *bufio.Reader) bool {
+	fmt.Println("Keep going? 'y' (Yes) / 'n' (No)")
+	for {
+		char, _, err := reader.ReadRune()
+		if err != nil {
+			return false
+		}
+		switch char {
+		case 'y', 'Y':
+			return true
+		case 'n', 'N', 'q', 'Q':
+			return false
+		}
 	}
-
-	return nil
 }